@@ -7,6 +7,7 @@ toolchain go1.26.3
 require (
 	github.com/autobrr/go-rtorrent v1.12.0
 	github.com/gdm85/go-libdeluge v0.6.0
+	github.com/gdm85/go-rencode v0.1.8
 	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/gorilla/handlers v1.5.2
 	github.com/knadh/koanf/parsers/toml/v2 v2.2.1
@@ -23,7 +24,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/felixge/httpsnoop v1.0.3 // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
-	github.com/gdm85/go-rencode v0.1.8 // indirect
 	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/knadh/koanf/maps v0.1.2 // indirect
@@ -2,10 +2,12 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 var _ Provider = (*JellyfinProvider)(nil)
@@ -16,26 +18,30 @@ const (
 )
 
 type JellyfinProvider struct {
-	baseUrl string
+	baseUrl    string
+	httpClient *http.Client
 }
 
-func NewJellyfinProvider(baseUrl string) *JellyfinProvider {
-	return &JellyfinProvider{baseUrl: baseUrl}
+func NewJellyfinProvider(baseUrl string, timeout time.Duration) *JellyfinProvider {
+	return &JellyfinProvider{baseUrl: baseUrl, httpClient: &http.Client{Timeout: timeout}}
 }
 
-func (provider JellyfinProvider) CheckCredentials(username string, password []byte) (bool, error) {
+func (provider JellyfinProvider) CheckCredentials(ctx context.Context, username string, password []byte) (bool, error) {
 	url := provider.baseUrl + "/Users/AuthenticateByName"
 	jsonData, _ := json.Marshal(map[string]string{
 		"Username": username,
 		"Pw":       string(password),
 	})
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return false, fmt.Errorf("failed to create auth request for jellyfin: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	embyAuthorization := fmt.Sprintf("MediaBrowser Client=\"Scrubarr\", Device=\"Scrubarr\", DeviceId=%q, Version=%q", deviceId, version)
 	req.Header.Set("X-Emby-Authorization", embyAuthorization)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := provider.httpClient.Do(req)
 	if err != nil {
 		return false, fmt.Errorf("failed to send auth request to jellyfin: %w", err)
 	}
@@ -65,7 +71,7 @@ func (provider JellyfinProvider) CheckCredentials(username string, password []by
 	if result.User.Policy.IsAdministrator || result.User.Policy.EnableCollectionManagement {
 		return true, nil
 	}
-	
+
 	return false, nil
 }
 
@@ -73,7 +79,7 @@ type JellyfinResponse struct {
 	User struct {
 		Name   string `json:"Name"`
 		Policy struct {
-			IsAdministrator bool `json:"IsAdministrator"`
+			IsAdministrator            bool `json:"IsAdministrator"`
 			EnableCollectionManagement bool `json:"EnableCollectionManagement"`
 		} `json:"Policy"`
 	} `json:"User"`
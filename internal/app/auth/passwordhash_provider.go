@@ -2,6 +2,7 @@ package auth
 
 import (
 	"bytes"
+	"context"
 
 	"golang.org/x/crypto/argon2"
 )
@@ -17,7 +18,7 @@ func NewPasswordBasedProvider(username string, passwordHash, passwordSalt []byte
 	return &PasswordBasedProvider{username: username, passwordHash: passwordHash, passwordSalt: passwordSalt}
 }
 
-func (provider PasswordBasedProvider) CheckCredentials(username string, password []byte) (bool, error) {
+func (provider PasswordBasedProvider) CheckCredentials(_ context.Context, username string, password []byte) (bool, error) {
 	passwordHashExpected := provider.passwordHash
 	incorrectUsername := provider.username != username
 	if incorrectUsername || !checkPassword(passwordHashExpected, password, provider.passwordSalt) {
@@ -1,7 +1,9 @@
 package auth
 
+import "context"
+
 type Provider interface {
-	CheckCredentials(username string, password []byte) (bool, error)
+	CheckCredentials(ctx context.Context, username string, password []byte) (bool, error)
 
 	Name() string
 }
@@ -0,0 +1,293 @@
+package scrubarr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/media"
+	"github.com/almanac1631/scrubarr/pkg/torrentclients"
+	"github.com/knadh/koanf/v2"
+)
+
+// errInvalidConnectionConfig marks configuration errors which cannot be resolved by reconnecting later.
+var errInvalidConnectionConfig = errors.New("invalid connection config")
+
+// connectionIdPattern restricts instance ids to characters that are safe to use inside media and torrent ids which are
+// separated by "-".
+var connectionIdPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// defaultConnectionRetryInterval is used to reconnect degraded sources if general.connection_retry_interval is not set.
+const defaultConnectionRetryInterval = time.Minute
+
+// defaultConnectionTimeout bounds every request to a source if its connection does not set a timeout.
+const defaultConnectionTimeout = 2 * time.Minute
+
+type mediaSourceLoader struct {
+	mediaType domain.MediaType
+	load      func(id string, config *koanf.Koanf) (domain.MediaSource, error)
+}
+
+var mediaSourceRegistry = map[string]mediaSourceLoader{
+	"radarr":  {domain.MediaTypeMovie, loadRadarrRetriever},
+	"sonarr":  {domain.MediaTypeSeries, loadSonarrRetriever},
+	"lidarr":  {domain.MediaTypeMusic, loadLidarrRetriever},
+	"readarr": {domain.MediaTypeBook, loadReadarrRetriever},
+}
+
+var torrentSourceRegistry = map[string]func(id string, config *koanf.Koanf) (domain.TorrentSource, error){
+	"deluge":       loadDelugeRetriever,
+	"rtorrent":     loadRtorrentRetriever,
+	"qbittorrent":  loadQbittorrentRetriever,
+	"transmission": loadTransmissionRetriever,
+}
+
+func getConnectionRetryInterval(k *koanf.Koanf) time.Duration {
+	if retryInterval := k.Duration("general.connection_retry_interval"); retryInterval > 0 {
+		return retryInterval
+	}
+	return defaultConnectionRetryInterval
+}
+
+func getConnectionTimeout(config *koanf.Koanf) time.Duration {
+	if timeout := config.Duration("timeout"); timeout > 0 {
+		return timeout
+	}
+	return defaultConnectionTimeout
+}
+
+type connection struct {
+	id             string
+	connectionType string
+	config         *koanf.Koanf
+}
+
+// getConnections returns all enabled connections configured as [connections.<id>]. The type of the connection is read
+// from the type key and defaults to the id so that e.g. [connections.sonarr] does not need an explicit type.
+func getConnections(k *koanf.Koanf) ([]connection, error) {
+	connections := make([]connection, 0)
+	for _, id := range k.MapKeys("connections") {
+		if !connectionIdPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid connection id %q: only letters, digits and underscores are allowed", id)
+		}
+		config := k.Cut(fmt.Sprintf("connections.%s", id))
+		if config.Exists("enabled") && !config.Bool("enabled") {
+			slog.Debug("Skipping disabled connection.", "connection", id)
+			continue
+		}
+		connectionType := config.String("type")
+		if connectionType == "" {
+			connectionType = id
+		}
+		_, isMediaSource := mediaSourceRegistry[connectionType]
+		_, isTorrentSource := torrentSourceRegistry[connectionType]
+		if !isMediaSource && !isTorrentSource {
+			return nil, fmt.Errorf("unknown type %q of connection %q", connectionType, id)
+		}
+		connections = append(connections, connection{id, connectionType, config})
+	}
+	return connections, nil
+}
+
+func getMediaSources(ctx context.Context, k *koanf.Koanf) ([]domain.MediaSource, error) {
+	connections, err := getConnections(k)
+	if err != nil {
+		return nil, err
+	}
+	retryInterval := getConnectionRetryInterval(k)
+	mediaSources := make([]domain.MediaSource, 0)
+	for _, connection := range connections {
+		loader, ok := mediaSourceRegistry[connection.connectionType]
+		if !ok {
+			continue
+		}
+		connect := func() (domain.MediaSource, error) {
+			return loader.load(connection.id, connection.config)
+		}
+		mediaSource, err := connect()
+		if errors.Is(err, errInvalidConnectionConfig) {
+			return nil, fmt.Errorf("could not setup %s connection %q: %w", connection.connectionType, connection.id, err)
+		} else if err != nil {
+			slog.Warn("Could not set up media source. Marking it as degraded.", "connection", connection.id, "type", connection.connectionType, "error", err)
+			mediaSource = media.NewDegradedMediaSource(ctx, connection.id, loader.mediaType, err, connect, retryInterval)
+		} else {
+			slog.Info("Successfully set up media source.", "connection", connection.id, "type", connection.connectionType)
+		}
+		mediaSources = append(mediaSources, mediaSource)
+	}
+	return mediaSources, nil
+}
+
+func getTorrentSources(ctx context.Context, k *koanf.Koanf) ([]domain.TorrentSource, error) {
+	connections, err := getConnections(k)
+	if err != nil {
+		return nil, err
+	}
+	retryInterval := getConnectionRetryInterval(k)
+	torrentSources := make([]domain.TorrentSource, 0)
+	for _, connection := range connections {
+		instantiator, ok := torrentSourceRegistry[connection.connectionType]
+		if !ok {
+			continue
+		}
+		connect := func() (domain.TorrentSource, error) {
+			return instantiator(connection.id, connection.config)
+		}
+		torrentSource, err := connect()
+		if errors.Is(err, errInvalidConnectionConfig) {
+			return nil, fmt.Errorf("could not setup %s connection %q: %w", connection.connectionType, connection.id, err)
+		} else if err != nil {
+			slog.Warn("Could not set up torrent source. Marking it as degraded.", "connection", connection.id, "type", connection.connectionType, "error", err)
+			torrentSource = torrentclients.NewDegradedTorrentSource(ctx, connection.id, err, connect, retryInterval)
+		} else {
+			slog.Info("Successfully set up torrent source.", "connection", connection.id, "type", connection.connectionType)
+		}
+		torrentSources = append(torrentSources, torrentSource)
+	}
+	return torrentSources, nil
+}
+
+// requireStrings returns the values of the given keys and fails if any of them is missing or empty.
+func requireStrings(config *koanf.Koanf, keys ...string) ([]string, error) {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := config.String(key)
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s is required", errInvalidConnectionConfig, key)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func loadRadarrRetriever(id string, config *koanf.Koanf) (domain.MediaSource, error) {
+	values, err := requireStrings(config, "hostname", "api_key")
+	if err != nil {
+		return nil, err
+	}
+	return media.NewRadarrRetriever(id, values[0], values[1], getConnectionTimeout(config), dryRun)
+}
+
+// defaultParallelism is the number of concurrent requests sent to sources which only support retrieving the files of a
+// single entry per request.
+const defaultParallelism = 4
+
+func getParallelism(config *koanf.Koanf) (int, error) {
+	if !config.Exists("parallelism") {
+		return defaultParallelism, nil
+	}
+	parallelism := config.Int("parallelism")
+	if parallelism <= 0 {
+		return 0, fmt.Errorf("%w: parallelism has to be positive", errInvalidConnectionConfig)
+	}
+	return parallelism, nil
+}
+
+func loadSonarrRetriever(id string, config *koanf.Koanf) (domain.MediaSource, error) {
+	values, err := requireStrings(config, "hostname", "api_key")
+	if err != nil {
+		return nil, err
+	}
+	parallelism, err := getParallelism(config)
+	if err != nil {
+		return nil, err
+	}
+	return media.NewSonarrRetriever(id, values[0], values[1], getConnectionTimeout(config), parallelism, dryRun)
+}
+
+func loadLidarrRetriever(id string, config *koanf.Koanf) (domain.MediaSource, error) {
+	values, err := requireStrings(config, "hostname", "api_key")
+	if err != nil {
+		return nil, err
+	}
+	parallelism, err := getParallelism(config)
+	if err != nil {
+		return nil, err
+	}
+	return media.NewLidarrRetriever(id, values[0], values[1], getConnectionTimeout(config), parallelism, dryRun)
+}
+
+func loadReadarrRetriever(id string, config *koanf.Koanf) (domain.MediaSource, error) {
+	values, err := requireStrings(config, "hostname", "api_key")
+	if err != nil {
+		return nil, err
+	}
+	parallelism, err := getParallelism(config)
+	if err != nil {
+		return nil, err
+	}
+	return media.NewReadarrRetriever(id, values[0], values[1], getConnectionTimeout(config), parallelism, dryRun)
+}
+
+// defaultSeedOnlyLabel is assigned to torrents whose media is deleted using domain.RemovalModeRelabel.
+const defaultSeedOnlyLabel = "seed-only"
+
+// delugeLabelRegex matches the label names accepted by the label plugin of deluge.
+var delugeLabelRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+func getSeedOnlyLabel(config *koanf.Koanf) string {
+	if label := config.String("seed_only_label"); label != "" {
+		return label
+	}
+	return defaultSeedOnlyLabel
+}
+
+func loadDelugeRetriever(id string, config *koanf.Koanf) (domain.TorrentSource, error) {
+	seedOnlyLabel := getSeedOnlyLabel(config)
+	if !delugeLabelRegex.MatchString(seedOnlyLabel) {
+		return nil, fmt.Errorf("%w: seed_only_label %q may only contain a-z, 0-9, _ and -", errInvalidConnectionConfig, seedOnlyLabel)
+	}
+	switch mode := config.String("mode"); mode {
+	case "", "daemon":
+	case "web":
+		values, err := requireStrings(config, "hostname", "password")
+		if err != nil {
+			return nil, err
+		}
+		return torrentclients.NewDelugeWebRetriever(id, values[0], values[1], seedOnlyLabel, getConnectionTimeout(config), dryRun)
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", errInvalidConnectionConfig, mode)
+	}
+	values, err := requireStrings(config, "hostname", "username", "password")
+	if err != nil {
+		return nil, err
+	}
+	port := config.Int("port")
+	if port <= 0 {
+		return nil, fmt.Errorf("%w: port is required", errInvalidConnectionConfig)
+	}
+	return torrentclients.NewDelugeRetriever(id, values[0], uint(port), values[1], values[2], seedOnlyLabel, getConnectionTimeout(config), dryRun)
+}
+
+func loadRtorrentRetriever(id string, config *koanf.Koanf) (domain.TorrentSource, error) {
+	values, err := requireStrings(config, "hostname")
+	if err != nil {
+		return nil, err
+	}
+	// SCGI sockets do not support authentication
+	return torrentclients.NewRtorrentRetriever(id, values[0], config.String("username"), config.String("password"), getSeedOnlyLabel(config), getConnectionTimeout(config), dryRun)
+}
+
+func loadQbittorrentRetriever(id string, config *koanf.Koanf) (domain.TorrentSource, error) {
+	values, err := requireStrings(config, "hostname", "username", "password")
+	if err != nil {
+		return nil, err
+	}
+	parallelism, err := getParallelism(config)
+	if err != nil {
+		return nil, err
+	}
+	return torrentclients.NewQbittorrentRetriever(id, values[0], values[1], values[2], getConnectionTimeout(config), parallelism, dryRun)
+}
+
+func loadTransmissionRetriever(id string, config *koanf.Koanf) (domain.TorrentSource, error) {
+	values, err := requireStrings(config, "hostname")
+	if err != nil {
+		return nil, err
+	}
+	return torrentclients.NewTransmissionRetriever(id, values[0], config.String("username"), config.String("password"), getConnectionTimeout(config), dryRun)
+}
@@ -0,0 +1,26 @@
+package scrubarr
+
+import (
+	"fmt"
+
+	"github.com/almanac1631/scrubarr/pkg/filesystem"
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+	"github.com/knadh/koanf/v2"
+)
+
+// getFilesystemInspector returns the inspector used to calculate the hardlink aware reclaimable size and to scan for
+// orphaned files or nil if it is disabled.
+func getFilesystemInspector(k *koanf.Koanf) (inventory.FilesystemInspector, error) {
+	if !k.Bool("filesystem.enabled") {
+		return nil, nil
+	}
+	pathMappings := make([]filesystem.PathMapping, 0)
+	for i, config := range k.Slices("filesystem.path_mappings") {
+		remote, local := config.String("remote"), config.String("local")
+		if remote == "" || local == "" {
+			return nil, fmt.Errorf("path mapping %d requires both remote and local", i)
+		}
+		pathMappings = append(pathMappings, filesystem.PathMapping{Remote: remote, Local: local})
+	}
+	return filesystem.NewInspector(pathMappings, k.Strings("filesystem.scan_roots"), dryRun), nil
+}
@@ -2,18 +2,26 @@ package scrubarr
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/almanac1631/scrubarr/internal/app/webserver"
 	"github.com/almanac1631/scrubarr/pkg/quota"
 	"github.com/knadh/koanf/v2"
 )
 
+// defaultQuotaTimeout bounds requests to the quota provider if quota.<provider>.timeout is not set.
+const defaultQuotaTimeout = 30 * time.Second
+
 func getQuotaService(k *koanf.Koanf) (webserver.QuotaService, error) {
 	provider := k.MustString("quota.provider")
 	if provider == "ultraapi" {
 		endpoint := k.MustString("quota.ultraapi.endpoint")
 		apiKey := k.MustBytes("quota.ultraapi.api_key")
-		return quota.NewUltraApiQuotaService(endpoint, apiKey), nil
+		timeout := k.Duration("quota.ultraapi.timeout")
+		if timeout <= 0 {
+			timeout = defaultQuotaTimeout
+		}
+		return quota.NewUltraApiQuotaService(endpoint, apiKey, timeout), nil
 	}
 	return nil, fmt.Errorf("unknown quota provider: %q", provider)
 }
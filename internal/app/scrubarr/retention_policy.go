@@ -0,0 +1,43 @@
+package scrubarr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/retentionpolicy"
+	"github.com/knadh/koanf/v2"
+)
+
+// getUnregisteredPatterns returns the patterns matching tracker messages of unregistered torrents. The defaults are used
+// if retention.unregistered_patterns is not set.
+func getUnregisteredPatterns(k *koanf.Koanf) ([]*regexp.Regexp, error) {
+	if !k.Exists("retention.unregistered_patterns") {
+		return retentionpolicy.DefaultUnregisteredPatterns, nil
+	}
+	patterns := make([]*regexp.Regexp, 0)
+	for _, patternRaw := range k.Strings("retention.unregistered_patterns") {
+		pattern, err := regexp.Compile(patternRaw)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile unregistered pattern %q: %w", patternRaw, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// getDeletableStates returns the torrent states configured in retention.deletable_states. Incomplete torrents are never
+// deletable, so only paused, queued and error are accepted.
+func getDeletableStates(k *koanf.Koanf) ([]domain.TorrentState, error) {
+	allowedStates := []domain.TorrentState{domain.TorrentStatePaused, domain.TorrentStateQueued, domain.TorrentStateError}
+	states := make([]domain.TorrentState, 0)
+	for _, stateRaw := range k.Strings("retention.deletable_states") {
+		state := domain.TorrentState(stateRaw)
+		if !slices.Contains(allowedStates, state) {
+			return nil, fmt.Errorf("invalid deletable state %q, must be one of %v", stateRaw, allowedStates)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
@@ -105,69 +105,62 @@ func serve(cmd *cobra.Command, args []string) {
 		slog.Info("Goodbye!")
 	}()
 
-	radarrRetriever, err := media.NewRadarrRetriever(
-		k.MustString("connections.radarr.hostname"),
-		k.MustString("connections.radarr.api_key"),
-		dryRun,
-	)
+	mediaSources, err := getMediaSources(ctx, k)
 	if err != nil {
-		slog.Error("Could not setup radarr retriever", "error", err)
+		slog.Error("Could not setup media sources", "error", err)
 		os.Exit(1)
 	}
 
-	sonarrRetriever, err := media.NewSonarrRetriever(
-		k.MustString("connections.sonarr.hostname"),
-		k.MustString("connections.sonarr.api_key"),
-		dryRun,
-	)
+	mediaManager := media.NewDefaultMediaManager(mediaSources...)
+
+	torrentSources, err := getTorrentSources(ctx, k)
 	if err != nil {
-		slog.Error("Could not setup sonarr retriever", "error", err)
+		slog.Error("Could not setup torrent sources", "error", err)
 		os.Exit(1)
 	}
 
-	mediaManager := media.NewDefaultMediaManager(radarrRetriever, sonarrRetriever)
+	torrentManager := torrentclients.NewDefaultTorrentManager(torrentSources...)
+
+	trackerResolver, err := trackerresolver.NewServiceFromKoanf(k)
+	if err != nil {
+		slog.Error("Could not setup tracker manager", "error", err)
+		os.Exit(1)
+	}
 
-	delugeRetriever, err := torrentclients.NewDelugeRetriever(
-		k.MustString("connections.deluge.hostname"),
-		uint(k.MustInt("connections.deluge.port")),
-		k.MustString("connections.deluge.username"),
-		k.MustString("connections.deluge.password"),
-		dryRun,
-	)
+	unregisteredPatterns, err := getUnregisteredPatterns(k)
 	if err != nil {
-		slog.Error("Could not setup deluge retriever", "error", err)
+		slog.Error("Could not setup retention policy", "error", err)
 		os.Exit(1)
 	}
 
-	rtorrentRetriever, err := torrentclients.NewRtorrentRetriever(
-		k.MustString("connections.rtorrent.hostname"),
-		k.MustString("connections.rtorrent.username"),
-		k.MustString("connections.rtorrent.password"),
-		dryRun,
-	)
+	deletableStates, err := getDeletableStates(k)
 	if err != nil {
-		slog.Error("Could not setup rtorrent retriever", "error", err)
+		slog.Error("Could not setup retention policy", "error", err)
 		os.Exit(1)
 	}
 
-	torrentManager := torrentclients.NewDefaultTorrentManager(delugeRetriever, rtorrentRetriever)
+	retentionPolicy := retentionpolicy.NewService(trackerResolver, unregisteredPatterns, deletableStates, k.Strings("retention.keep_tags"))
 
-	trackerResolver, err := trackerresolver.NewServiceFromKoanf(k)
+	filesystemInspector, err := getFilesystemInspector(k)
 	if err != nil {
-		slog.Error("Could not setup tracker manager", "error", err)
+		slog.Error("Could not setup filesystem inspector", "error", err)
 		os.Exit(1)
 	}
 
-	retentionPolicy := retentionpolicy.NewService(trackerResolver)
+	seedStore, err := getSeedStore(k)
+	if err != nil {
+		slog.Error("Could not setup seed store", "error", err)
+		os.Exit(1)
+	}
 
-	inventoryService := inventory.NewService(useCache, saveCache, mediaManager, torrentManager, linker.NewService(), retentionPolicy)
+	inventoryService := inventory.NewService(useCache, saveCache, mediaManager, torrentManager, linker.NewService(), retentionPolicy, filesystemInspector, seedStore)
 
 	refreshInterval := k.Duration("general.refresh_interval")
 	refreshCaches := func() {
 		slog.Debug("Refreshing retriever data...")
-		if err := inventoryService.RefreshCache(); err != nil {
-			slog.Error("Could not refresh cache of inventory service.", "error", err)
-			os.Exit(1)
+		if err := inventoryService.RefreshCache(ctx); err != nil {
+			slog.Error("Could not refresh cache of inventory service. Retrying on next refresh.", "error", err)
+			return
 		}
 		slog.Debug("Refreshed retriever data.")
 	}
@@ -204,6 +197,10 @@ func serve(cmd *cobra.Command, args []string) {
 		ReadTimeout:       10 * time.Second,
 		WriteTimeout:      15 * time.Second,
 		IdleTimeout:       60 * time.Second,
+		// pending requests are cancelled on shutdown
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
 	}
 	err = srv.Serve(listener)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) && err.Error() != "" {
@@ -0,0 +1,24 @@
+package scrubarr
+
+import (
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+	"github.com/almanac1631/scrubarr/pkg/seedstore"
+	"github.com/knadh/koanf/v2"
+)
+
+// minObservationGap is the least seeding time accounted between two refreshes if the automatic refresh is disabled or
+// runs more often.
+const minObservationGap = time.Hour
+
+// getSeedStore returns the store used to observe seeding times or nil if general.seed_store_path is not set.
+func getSeedStore(k *koanf.Koanf) (inventory.SeedStore, error) {
+	path := k.String("general.seed_store_path")
+	if path == "" {
+		return nil, nil
+	}
+	// refreshes might be delayed, so allow a bit more than the refresh interval between two observations
+	maxObservationGap := max(2*k.Duration("general.refresh_interval"), minObservationGap)
+	return seedstore.NewStore(path, maxObservationGap)
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almanac1631/scrubarr/internal/app/auth"
 	"github.com/knadh/koanf/v2"
@@ -58,10 +59,20 @@ func loadPasswordHashAuthProvider(config map[string]string) (auth.Provider, erro
 	return auth.NewPasswordBasedProvider(username, passwordHash, passwordSalt), nil
 }
 
+// defaultJellyfinTimeout bounds the authentication requests to jellyfin if no timeout is configured.
+const defaultJellyfinTimeout = 30 * time.Second
+
 func loadJellyfinAuthProvider(config map[string]string) (auth.Provider, error) {
 	baseUrl := config["base_url"]
 	if baseUrl == "" {
 		return nil, fmt.Errorf("base_url is required")
 	}
-	return auth.NewJellyfinProvider(baseUrl), nil
+	timeout := defaultJellyfinTimeout
+	if rawTimeout := config["timeout"]; rawTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(rawTimeout); err != nil {
+			return nil, fmt.Errorf("error parsing timeout %q: %w", rawTimeout, err)
+		}
+	}
+	return auth.NewJellyfinProvider(baseUrl, timeout), nil
 }
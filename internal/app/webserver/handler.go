@@ -74,7 +74,7 @@ func (handler *handler) renderPage(
 		}
 		return
 	}
-	diskQuota, err := handler.quotaService.GetDiskQuota()
+	diskQuota, err := handler.quotaService.GetDiskQuota(request.Context())
 	if err != nil {
 		logger.Error("could not get disk quota", "err", err)
 	}
@@ -31,7 +31,7 @@ func (handler *handler) handleLogin(writer http.ResponseWriter, request *http.Re
 			http.Error(writer, "password is required", http.StatusBadRequest)
 			return
 		}
-		ok, err := handler.authProvider.CheckCredentials(username, password)
+		ok, err := handler.authProvider.CheckCredentials(request.Context(), username, password)
 		if err != nil {
 			slog.Error("Error checking credentials", "error", err)
 			http.Error(writer, err.Error(), http.StatusInternalServerError)
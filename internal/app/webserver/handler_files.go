@@ -0,0 +1,64 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type filesEndpointData struct {
+	basePageData
+	Rows []OrphanedFileRow
+}
+
+func (handler *handler) handleFilesEndpoint(writer http.ResponseWriter, request *http.Request) {
+	handler.renderPage(writer, request, "files.gohtml", "Orphaned files", func(base basePageData) any {
+		return filesEndpointData{basePageData: base}
+	})
+}
+
+func (handler *handler) handleFileEntriesEndpoint(writer http.ResponseWriter, request *http.Request) {
+	logger := getRequestLogger(request)
+	sortInfo := getSortInfoFromUrlQuery(request.URL.Query())
+	pageRaw := request.URL.Query().Get("page")
+	page, _ := strconv.Atoi(pageRaw)
+	if page < 1 {
+		page = 1
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rows, hasNext, err := handler.inventoryService.GetOrphanedFiles(request.Context(), page, sortInfo)
+	if err != nil {
+		logger.Error("Failed to get orphaned files.", "error", err)
+		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	nextPage := -1
+	if hasNext {
+		nextPage = page + 1
+	}
+	if err = handler.ExecuteSubTemplate(writer, "files.gohtml", "file_entries", filesEndpointData{
+		basePageData: basePageData{SortInfo: sortInfo, NextPage: nextPage},
+		Rows:         rows,
+	}); err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+func (handler *handler) handleFileDeletionEndpoint(writer http.ResponseWriter, request *http.Request) {
+	logger := getRequestLogger(request)
+	id := request.PathValue("id")
+	logger = logger.With("id", id)
+	logger.Debug("Deleting orphaned file...")
+	if err := handler.inventoryService.DeleteOrphanedFile(id); errors.Is(err, ErrMediaNotFound) {
+		writer.Header().Set("Hx-Trigger", "diskQuotaUpdate")
+		writer.WriteHeader(http.StatusOK)
+		return
+	} else if err != nil {
+		logger.Error("Could not delete orphaned file.", "error", err)
+		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("Successfully deleted orphaned file.")
+	writer.Header().Set("Hx-Trigger", "diskQuotaUpdate")
+	writer.WriteHeader(http.StatusOK)
+}
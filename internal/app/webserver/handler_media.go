@@ -1,32 +1,39 @@
 package webserver
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
 )
 
 type mediaEndpointData struct {
 	basePageData
-	Rows []MediaRow
+	Filter        MediaFilter
+	FilterOptions MediaFilterOptions
+	Rows          []MediaRow
 }
 
 func (handler *handler) handleMediaEndpoint(writer http.ResponseWriter, request *http.Request) {
+	filter := getMediaFilterFromUrlQuery(request.URL.Query())
 	handler.renderPage(writer, request, "media.gohtml", "Media", func(base basePageData) any {
-		return mediaEndpointData{basePageData: base}
+		return mediaEndpointData{basePageData: base, Filter: filter, FilterOptions: handler.inventoryService.GetMediaFilterOptions()}
 	})
 }
 
 func (handler *handler) handleMediaEntriesEndpoint(writer http.ResponseWriter, request *http.Request) {
 	logger := getRequestLogger(request)
 	sortInfo := getSortInfoFromUrlQuery(request.URL.Query())
+	filter := getMediaFilterFromUrlQuery(request.URL.Query())
 	pageRaw := request.URL.Query().Get("page")
 	page, _ := strconv.Atoi(pageRaw)
 	if page < 1 {
 		page = 1
 	}
 	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
-	mediaRows, hasNext, err := handler.inventoryService.GetMediaInventory(page, sortInfo)
+	mediaRows, hasNext, err := handler.inventoryService.GetMediaInventory(request.Context(), page, sortInfo, filter)
 	if err != nil {
 		logger.Error("Failed to get media mapping.", "error", err)
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
@@ -38,6 +45,7 @@ func (handler *handler) handleMediaEntriesEndpoint(writer http.ResponseWriter, r
 	}
 	if err = handler.ExecuteSubTemplate(writer, "media.gohtml", "media_entries", mediaEndpointData{
 		basePageData: basePageData{SortInfo: sortInfo, NextPage: nextPage},
+		Filter:       filter,
 		Rows:         mediaRows,
 	}); err != nil {
 		logger.Error(err.Error())
@@ -73,9 +81,19 @@ func (handler *handler) serveMediaSeriesEntry(writer http.ResponseWriter, reques
 func (handler *handler) handleMediaDeletionEndpoint(writer http.ResponseWriter, request *http.Request) {
 	logger := getRequestLogger(request)
 	id := request.PathValue("id")
-	logger = logger.With("id", id)
+	mode, err := domain.ParseRemovalMode(request.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(writer, "400 Bad Request", http.StatusBadRequest)
+		return
+	}
+	logger = logger.With("id", id, "mode", mode)
+	// deletions are not cancelled if the client disconnects as they consist of several calls
 	logger.Debug("Deleting media...")
-	if err := handler.inventoryService.DeleteMedia(id); err != nil {
+	if err = handler.inventoryService.DeleteMedia(context.WithoutCancel(request.Context()), id, mode); errors.Is(err, domain.ErrRemovalModeNotSupported) {
+		logger.Warn("Could not delete media.", "error", err)
+		http.Error(writer, "400 Bad Request", http.StatusBadRequest)
+		return
+	} else if err != nil {
 		logger.Error("Could not delete media.", "error", err)
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
 		return
@@ -100,7 +118,7 @@ func (handler *handler) handleMediaDeletionEndpoint(writer http.ResponseWriter,
 func (handler *handler) handleRefreshEndpoint(writer http.ResponseWriter, request *http.Request) {
 	logger := getRequestLogger(request)
 	logger.Info("Refreshing media entries cache.")
-	if err := handler.inventoryService.RefreshCache(); err != nil {
+	if err := handler.inventoryService.RefreshCache(request.Context()); err != nil {
 		logger.Error(err.Error())
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
 		return
@@ -4,7 +4,7 @@ import "net/http"
 
 func (handler *handler) handleDiskQuotaEndpoint(writer http.ResponseWriter, request *http.Request) {
 	logger := getRequestLogger(request)
-	diskQuota, err := handler.quotaService.GetDiskQuota()
+	diskQuota, err := handler.quotaService.GetDiskQuota(request.Context())
 	if err != nil {
 		logger.Error("could not get disk quota", "err", err)
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
@@ -1,9 +1,12 @@
 package webserver
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
 )
 
 type torrentsEndpointData struct {
@@ -26,7 +29,7 @@ func (handler *handler) handleTorrentEntriesEndpoint(writer http.ResponseWriter,
 		page = 1
 	}
 	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
-	rows, hasNext, err := handler.inventoryService.GetOrphanedTorrents(page, sortInfo)
+	rows, hasNext, err := handler.inventoryService.GetOrphanedTorrents(request.Context(), page, sortInfo)
 	if err != nil {
 		logger.Error("Failed to get orphaned torrents.", "error", err)
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
@@ -47,12 +50,22 @@ func (handler *handler) handleTorrentEntriesEndpoint(writer http.ResponseWriter,
 func (handler *handler) handleTorrentDeletionEndpoint(writer http.ResponseWriter, request *http.Request) {
 	logger := getRequestLogger(request)
 	id := request.PathValue("id")
-	logger = logger.With("id", id)
+	mode, err := domain.ParseRemovalMode(request.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(writer, "400 Bad Request", http.StatusBadRequest)
+		return
+	}
+	logger = logger.With("id", id, "mode", mode)
+	// deletions are not cancelled if the client disconnects as they consist of several calls
 	logger.Debug("Deleting orphaned torrent...")
-	if err := handler.inventoryService.DeleteOrphanedTorrent(id); errors.Is(err, ErrMediaNotFound) {
+	if err = handler.inventoryService.DeleteOrphanedTorrent(context.WithoutCancel(request.Context()), id, mode); errors.Is(err, ErrMediaNotFound) {
 		writer.Header().Set("Hx-Trigger", "diskQuotaUpdate")
 		writer.WriteHeader(http.StatusOK)
 		return
+	} else if errors.Is(err, domain.ErrRemovalModeNotSupported) {
+		logger.Warn("Could not delete orphaned torrent.", "error", err)
+		http.Error(writer, "400 Bad Request", http.StatusBadRequest)
+		return
 	} else if err != nil {
 		logger.Error("Could not delete orphaned torrent.", "error", err)
 		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
@@ -60,5 +73,22 @@ func (handler *handler) handleTorrentDeletionEndpoint(writer http.ResponseWriter
 	}
 	logger.Info("Successfully deleted orphaned torrent.")
 	writer.Header().Set("Hx-Trigger", "diskQuotaUpdate")
-	writer.WriteHeader(http.StatusOK)
+	if mode != domain.RemovalModePause && mode != domain.RemovalModeRelabel {
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+	// paused and relabeled torrents stay in the list
+	row, err := handler.inventoryService.GetOrphanedTorrent(id)
+	if errors.Is(err, ErrMediaNotFound) {
+		writer.WriteHeader(http.StatusOK)
+		return
+	} else if err != nil {
+		logger.Error(err.Error())
+		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err = handler.ExecuteSubTemplate(writer, "torrents.gohtml", "torrent_entry", row); err != nil {
+		logger.Error(err.Error())
+		http.Error(writer, "500 Internal Server Error", http.StatusInternalServerError)
+	}
 }
@@ -37,3 +37,19 @@ func getSortInfoFromUrlQuery(values url.Values) SortInfo {
 	}
 	return sortInfo
 }
+
+func getMediaFilterFromUrlQuery(values url.Values) MediaFilter {
+	filter := MediaFilter{
+		Tag:            values.Get("tag"),
+		QualityProfile: values.Get("qualityProfile"),
+		RootFolder:     values.Get("rootFolder"),
+	}
+	// invalid boolean filters are ignored like invalid sort keys
+	if monitored := values.Get("monitored"); monitored == "true" || monitored == "false" {
+		filter.Monitored = monitored
+	}
+	if ended := values.Get("ended"); ended == "true" || ended == "false" {
+		filter.Ended = ended
+	}
+	return filter
+}
@@ -50,3 +50,50 @@ func Test_getSortInfoFromUrlQuery(t *testing.T) {
 		})
 	}
 }
+
+func Test_getMediaFilterFromUrlQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   MediaFilter
+	}{
+		{
+			name: "test valid parse from url query",
+			values: map[string][]string{
+				"tag":            {"keep"},
+				"qualityProfile": {"HD-1080p"},
+				"rootFolder":     {"/media/series"},
+				"monitored":      {"false"},
+				"ended":          {"true"},
+			},
+			want: MediaFilter{
+				Tag:            "keep",
+				QualityProfile: "HD-1080p",
+				RootFolder:     "/media/series",
+				Monitored:      "false",
+				Ended:          "true",
+			},
+		},
+		{
+			name: "test invalid boolean filters are ignored",
+			values: map[string][]string{
+				"monitored": {"yes"},
+				"ended":     {"1"},
+			},
+			want: MediaFilter{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getMediaFilterFromUrlQuery(tt.values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getMediaFilterFromUrlQuery() = %v, want %v", got, tt.want)
+			}
+			// the query of the filter results in the same filter
+			query, _ := url.ParseQuery(got.Query())
+			if roundTrip := getMediaFilterFromUrlQuery(query); roundTrip != got {
+				t.Errorf("getMediaFilterFromUrlQuery(Query()) = %v, want %v", roundTrip, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package webserver
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
 
 var ErrMalformedMediaId = errors.New("malformed media id")
 var ErrMediaNotFound = errors.New("media not found")
@@ -26,16 +32,61 @@ type SortInfo struct {
 	Order SortOrder
 }
 
+// MediaFilter restricts the media inventory to the entries matching all of its non-empty fields.
+type MediaFilter struct {
+	Tag            string
+	QualityProfile string
+	RootFolder     string
+	// Monitored and Ended are either empty, "true" or "false".
+	Monitored string
+	Ended     string
+}
+
+// Query returns the url query of the non-empty fields, so that the filter is kept when sorting or paging.
+func (f MediaFilter) Query() string {
+	values := make(url.Values)
+	for key, value := range map[string]string{
+		"tag":            f.Tag,
+		"qualityProfile": f.QualityProfile,
+		"rootFolder":     f.RootFolder,
+		"monitored":      f.Monitored,
+		"ended":          f.Ended,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values.Encode()
+}
+
+// MediaFilterOptions contain the sorted values of all media the inventory can be filtered by.
+type MediaFilterOptions struct {
+	Tags            []string
+	QualityProfiles []string
+	RootFolders     []string
+}
+
 type InventoryService interface {
-	GetMediaInventory(page int, sortInfo SortInfo) (mediaRows []MediaRow, hasNext bool, err error)
+	GetMediaInventory(ctx context.Context, page int, sortInfo SortInfo, filter MediaFilter) (mediaRows []MediaRow, hasNext bool, err error)
+
+	// GetMediaFilterOptions returns the values of the cached media. They are empty if the cache has not been loaded yet.
+	GetMediaFilterOptions() MediaFilterOptions
 
 	GetExpandedMediaRow(id string) (mediaRow MediaRow, err error)
 
-	DeleteMedia(id string) error
+	// DeleteMedia deletes the torrents of the media using the given mode. The media files are only deleted for
+	// domain.RemovalModeRemoveWithData and domain.RemovalModeRelabel.
+	DeleteMedia(ctx context.Context, id string, mode domain.RemovalMode) error
+
+	RefreshCache(ctx context.Context) error
+
+	GetOrphanedTorrents(ctx context.Context, page int, sortInfo SortInfo) (rows []OrphanedTorrentRow, hasNext bool, err error)
+
+	GetOrphanedTorrent(id string) (row OrphanedTorrentRow, err error)
 
-	RefreshCache() error
+	DeleteOrphanedTorrent(ctx context.Context, id string, mode domain.RemovalMode) error
 
-	GetOrphanedTorrents(page int, sortInfo SortInfo) (rows []OrphanedTorrentRow, hasNext bool, err error)
+	GetOrphanedFiles(ctx context.Context, page int, sortInfo SortInfo) (rows []OrphanedFileRow, hasNext bool, err error)
 
-	DeleteOrphanedTorrent(id string) error
+	DeleteOrphanedFile(id string) error
 }
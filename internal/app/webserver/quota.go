@@ -1,10 +1,12 @@
 package webserver
 
+import "context"
+
 type DiskQuota struct {
 	UsedSpacePercentage              float64
 	UsedSpace, TotalSpace, FreeSpace int64
 }
 
 type QuotaService interface {
-	GetDiskQuota() (DiskQuota, error)
+	GetDiskQuota(ctx context.Context) (DiskQuota, error)
 }
@@ -11,6 +11,9 @@ import (
 var templateFunctions = template.FuncMap{
 	"formatBytes": utils.FormatBytes,
 	"formatDate": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
 		// Go uses a reference date (Mon Jan 2 15:04:05 MST 2006) for layout
 		return t.Format("2006-01-02")
 	},
@@ -20,6 +23,9 @@ var templateFunctions = template.FuncMap{
 	"durationToNanoseconds": func(duration time.Duration) int64 {
 		return duration.Nanoseconds()
 	},
+	"durationToDays": func(duration time.Duration) int64 {
+		return int64(duration.Hours() / 24)
+	},
 	"floatToStr": func(float float64) string {
 		return fmt.Sprintf("%.2f", float)
 	},
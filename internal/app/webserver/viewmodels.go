@@ -2,6 +2,7 @@ package webserver
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
@@ -20,22 +21,37 @@ type TorrentInformation struct {
 
 	Tracker domain.Tracker
 
-	Ratio float64
-	Age   time.Duration
+	// Ratio, Age and SeedingTime refer to the limiting torrent if the media is seeded by multiple torrents.
+	Ratio        float64
+	Age          time.Duration
+	SeedingTime  time.Duration
+	TorrentCount int
+	// State is the state of the first torrent not seeding or seeding if all are.
+	State domain.TorrentState
+	// Labels are the comma separated labels of all torrents. A string keeps TorrentInformation comparable.
+	Labels string
 }
 
 type MediaRow struct {
 	Id    string
 	Type  domain.MediaType
 	Title string
-	Url   string
-	Size  int64
-	Added time.Time
+	// Details describe the file of a row like its episodes, quality and release group. It is empty for grouping rows.
+	Details string
+	// Tags are the *arr tags of the media. They are empty for child rows.
+	Tags []string
+	Url  string
+	Size int64
+	// ReclaimableSize is the number of bytes actually freed on deletion respecting hardlinks or -1 if unknown.
+	ReclaimableSize int64
+	Added           time.Time
 
 	TorrentInformation TorrentInformation
 	Decision           domain.Decision
 
 	AllowDeletion bool
+	// RemovalModes are the removal modes supported by the clients of all torrents of the media.
+	RemovalModes []domain.RemovalMode
 
 	ChildMediaRows []MediaRow
 }
@@ -44,6 +60,11 @@ func (m MediaRow) String() string {
 	return fmt.Sprintf("id=%s", m.Id)
 }
 
+// SupportsRemovalMode reports whether the torrents of the media can be deleted using the given mode.
+func (m MediaRow) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	return slices.Contains(m.RemovalModes, mode)
+}
+
 type TorrentLinkStatus string
 
 const (
@@ -52,15 +73,42 @@ const (
 	TorrentLinkIncomplete TorrentLinkStatus = "incomplete"
 )
 
+type OrphanedFileRow struct {
+	Id   string
+	Path string
+	Size int64
+	// ReclaimableSize is the number of bytes actually freed on deletion respecting hardlinks.
+	ReclaimableSize int64
+	Modified        time.Time
+	Age             time.Duration
+	AllowDeletion   bool
+}
+
 type OrphanedTorrentRow struct {
-	Id            string
-	Name          string
-	Client        string
-	Ratio         float64
-	Added         time.Time
-	Age           time.Duration
-	Size          int64
-	Decision      domain.Decision
-	Tracker       domain.Tracker
+	Id     string
+	Name   string
+	Client string
+	Ratio  float64
+	State  domain.TorrentState
+	Labels []string
+	// Added is zero and Age is -1 if the torrent has not been completed yet.
+	Added time.Time
+	Age   time.Duration
+	// SeedingTime is -1 if the client does not report it.
+	SeedingTime time.Duration
+	Size        int64
+	// ReclaimableSize is the number of bytes actually freed on deletion respecting hardlinks or -1 if unknown.
+	ReclaimableSize int64
+	Decision        domain.Decision
+	Tracker         domain.Tracker
+	// TrackerStatus is the last message reported by the tracker.
+	TrackerStatus string
 	AllowDeletion bool
+	// RemovalModes are the removal modes supported by the client of the torrent.
+	RemovalModes []domain.RemovalMode
+}
+
+// SupportsRemovalMode reports whether the torrent can be deleted using the given mode.
+func (t OrphanedTorrentRow) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	return slices.Contains(t.RemovalModes, mode)
 }
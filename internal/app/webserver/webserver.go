@@ -67,6 +67,9 @@ func SetupWebserver(config *koanf.Koanf, version string, inventoryService Invent
 	authorizedRouter.HandleFunc("GET /torrents", handler.handleTorrentsEndpoint)
 	authorizedRouter.HandleFunc("GET /torrents/entries", htmxOnly(handler.handleTorrentEntriesEndpoint))
 	authorizedRouter.HandleFunc("DELETE /torrents/entries/{id}", htmxOnly(handler.handleTorrentDeletionEndpoint))
+	authorizedRouter.HandleFunc("GET /files", handler.handleFilesEndpoint)
+	authorizedRouter.HandleFunc("GET /files/entries", htmxOnly(handler.handleFileEntriesEndpoint))
+	authorizedRouter.HandleFunc("DELETE /files/entries/{id}", htmxOnly(handler.handleFileDeletionEndpoint))
 	authorizedRouter.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
 		if request.URL.Path != "/" {
 			http.NotFound(writer, request)
@@ -1,9 +1,34 @@
 package domain
 
-import "io"
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+)
+
+// ErrSourceDegraded is returned by sources that are currently unreachable. Managers keep serving the last known
+// entries of such sources.
+var ErrSourceDegraded = errors.New("source degraded")
+
+// IsConnectionError reports whether the error is caused by a source that cannot be reached, like a failed dial, a
+// timeout or a connection closed by the remote end. Sources that are still degraded are unreachable as well. Other
+// errors like authentication or parse errors do not go away by retrying and are not connection errors.
+func IsConnectionError(err error) bool {
+	if errors.Is(err, ErrSourceDegraded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
 
 type CachedManager interface {
-	RefreshCache() error
+	RefreshCache(ctx context.Context) error
 
 	SaveCache(writer io.Writer) error
 
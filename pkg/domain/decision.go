@@ -5,4 +5,7 @@ type Decision string
 const (
 	DecisionSafeToDelete Decision = "safe_to_delete"
 	DecisionPending      Decision = "pending"
+	// DecisionUnregistered marks torrents deleted or trumped by their tracker. They cannot earn ratio anymore and are
+	// therefore safe to delete as well.
+	DecisionUnregistered Decision = "unregistered"
 )
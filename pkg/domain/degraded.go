@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DegradedSource holds a source that could not be set up. It keeps trying to connect in the background until it
+// succeeds or the context passed to NewDegradedSource is done.
+type DegradedSource[T any] struct {
+	lock      sync.RWMutex
+	source    T
+	connected bool
+	lastErr   error
+}
+
+func NewDegradedSource[T any](ctx context.Context, name string, connectErr error, connect func() (T, error), retryInterval time.Duration) *DegradedSource[T] {
+	degradedSource := &DegradedSource[T]{lastErr: connectErr}
+	go degradedSource.reconnect(ctx, name, connect, retryInterval)
+	return degradedSource
+}
+
+func (degradedSource *DegradedSource[T]) reconnect(ctx context.Context, name string, connect func() (T, error), retryInterval time.Duration) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		source, err := connect()
+		degradedSource.lock.Lock()
+		if err == nil {
+			degradedSource.source = source
+			degradedSource.connected = true
+			degradedSource.lastErr = nil
+			degradedSource.lock.Unlock()
+			slog.Info("Degraded source is available again.", "connection", name)
+			return
+		}
+		degradedSource.lastErr = err
+		degradedSource.lock.Unlock()
+		slog.Warn("Could not connect degraded source. Retrying...", "connection", name, "error", err, "retryInterval", retryInterval)
+	}
+}
+
+// Get returns the connected source. Until the source is connected, it fails with ErrSourceDegraded wrapping the last
+// connection error.
+func (degradedSource *DegradedSource[T]) Get() (T, error) {
+	degradedSource.lock.RLock()
+	defer degradedSource.lock.RUnlock()
+	if !degradedSource.connected {
+		var zero T
+		return zero, fmt.Errorf("%w: %w", ErrSourceDegraded, degradedSource.lastErr)
+	}
+	return degradedSource.source, nil
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDegradedSource_Reconnect(t *testing.T) {
+	connectErr := errors.New("connection refused")
+	var attempts atomic.Int64
+	connect := func() (string, error) {
+		if attempts.Add(1) < 3 {
+			return "", connectErr
+		}
+		return "source", nil
+	}
+	degradedSource := NewDegradedSource(t.Context(), "test", connectErr, connect, time.Millisecond)
+
+	_, err := degradedSource.Get()
+	assert.ErrorIs(t, err, ErrSourceDegraded)
+	assert.ErrorIs(t, err, connectErr)
+
+	assert.Eventually(t, func() bool {
+		source, err := degradedSource.Get()
+		return err == nil && source == "source"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int64(3), attempts.Load())
+}
+
+func TestDegradedSource_Cancel(t *testing.T) {
+	connectErr := errors.New("connection refused")
+	var attempts atomic.Int64
+	connect := func() (string, error) {
+		attempts.Add(1)
+		return "", connectErr
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	degradedSource := NewDegradedSource(ctx, "test", connectErr, connect, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return attempts.Load() > 0
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	// wait for a pending attempt to finish before asserting that no further attempts are made
+	time.Sleep(10 * time.Millisecond)
+	attemptsAfterCancel := attempts.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, attemptsAfterCancel, attempts.Load())
+	_, err := degradedSource.Get()
+	assert.ErrorIs(t, err, ErrSourceDegraded)
+}
+
+func TestIsConnectionError(t *testing.T) {
+	_, dialErr := net.Dial("tcp", "127.0.0.1:0")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dial error", fmt.Errorf("could not get torrents: %w", dialErr), true},
+		{"dns error", &url.Error{Op: "Get", URL: "http://unknown.invalid", Err: &net.DNSError{Err: "no such host", Name: "unknown.invalid"}}, true},
+		{"timeout", &url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded}, true},
+		{"connection closed", fmt.Errorf("could not read response: %w", io.ErrUnexpectedEOF), true},
+		{"degraded source", fmt.Errorf("%w: %w", ErrSourceDegraded, errors.New("connection refused")), true},
+		{"authentication error", errors.New("could not login: unauthorized"), false},
+		{"parse error", fmt.Errorf("could not parse response: %w", &json.SyntaxError{}), false},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsConnectionError(tt.err))
+		})
+	}
+}
@@ -1,27 +1,62 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type MediaType string
 
 const (
 	MediaTypeMovie  MediaType = "movie"
 	MediaTypeSeries MediaType = "series"
+	MediaTypeMusic  MediaType = "music"
+	MediaTypeBook   MediaType = "book"
 )
 
 type MediaMetadata struct {
-	Id    int64
-	Type  MediaType
-	Title string
-	Url   string
-	Added time.Time
+	// Instance is the id of the configured *arr instance the media belongs to.
+	Instance string
+	Id       int64
+	Type     MediaType
+	Title    string
+	Url      string
+	Added    time.Time
+	// Tags are the labels of the *arr tags of the media like "keep".
+	Tags           []string
+	QualityProfile string
+	// RootFolder is the root folder of the media on the *arr host.
+	RootFolder string
+	Monitored  bool
+	// Ended reports whether a series has ended. It is always false for other media types.
+	Ended bool
 }
 
 type MediaFile struct {
-	Id               int64
-	Season           int
+	Id int64
+	// Season is the season number of series, the album id of music or the book id of books. Files are grouped by it in the media view.
+	Season int
+	// SeasonTitle replaces the default season title if set, e.g. with the album title.
+	SeasonTitle      string
 	OriginalFilePath string
-	Size             int64
+	// Path is the absolute path of the file on the *arr host.
+	Path string
+	Size int64
+	// DownloadId is the id of the download the file was imported from as reported by the *arr history. For torrents,
+	// this is the info hash. It is empty if the history does not contain the import.
+	DownloadId string
+
+	// EpisodeNumbers are the numbers of the episodes contained in the file. Multi-episode files contain several.
+	EpisodeNumbers []int
+	// EpisodeTitle is the title of the first episode contained in the file.
+	EpisodeTitle string
+	// AirDate is the air date of the first episode contained in the file or zero if unknown.
+	AirDate time.Time
+	// Quality is the quality name as reported by the *arr like "WEBDL-1080p".
+	Quality string
+	// Resolution is the vertical resolution of the file or 0 if unknown.
+	Resolution   int
+	ReleaseGroup string
 }
 
 type MediaEntry struct {
@@ -31,12 +66,14 @@ type MediaEntry struct {
 
 type MediaSourceManager interface {
 	CachedManager
-	GetMedia() ([]*MediaEntry, error)
-	DeleteMediaFiles(mediaType MediaType, fileIds []int64, stopParentMonitoring bool) error
+	GetMedia(ctx context.Context) ([]*MediaEntry, error)
+	DeleteMediaFiles(ctx context.Context, instance string, fileIds []int64, stopParentMonitoring bool) error
 }
 
 type MediaSource interface {
-	GetMedia() ([]MediaEntry, error)
+	GetMedia(ctx context.Context) ([]MediaEntry, error)
 	SupportedMediaType() MediaType
-	DeleteMediaFiles(fileIds []int64, stopParentMonitoring bool) error
+	DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error
+	// Name returns the id of the configured instance.
+	Name() string
 }
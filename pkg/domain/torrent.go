@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,19 +9,79 @@ import (
 
 var ErrTorrentNotFound = errors.New("torrent not found")
 
+var ErrRemovalModeNotSupported = errors.New("removal mode not supported by torrent client")
+
+// RemovalMode determines what happens to a torrent and its data when it is deleted.
+type RemovalMode string
+
+const (
+	RemovalModeRemoveWithData RemovalMode = "remove_with_data"
+	// RemovalModeRemoveKeepData removes the torrent from the client but keeps its data, e.g. hardlinked library copies.
+	RemovalModeRemoveKeepData RemovalMode = "remove_keep_data"
+	// RemovalModePause stops seeding the torrent but keeps it in the client.
+	RemovalModePause RemovalMode = "pause"
+	// RemovalModeRelabel keeps the torrent seeding but moves it to the seed-only label of the client. Media using this
+	// mode is deleted while its torrents show up as orphans until they are safe to delete.
+	RemovalModeRelabel RemovalMode = "relabel"
+)
+
+// RemovalModes are all removal modes in the order they are offered to the user.
+var RemovalModes = []RemovalMode{RemovalModePause, RemovalModeRemoveKeepData, RemovalModeRelabel, RemovalModeRemoveWithData}
+
+// ParseRemovalMode returns the removal mode of the given string. An empty string defaults to RemovalModeRemoveWithData.
+func ParseRemovalMode(mode string) (RemovalMode, error) {
+	switch RemovalMode(mode) {
+	case "":
+		return RemovalModeRemoveWithData, nil
+	case RemovalModeRemoveWithData, RemovalModeRemoveKeepData, RemovalModePause, RemovalModeRelabel:
+		return RemovalMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown removal mode %q", mode)
+	}
+}
+
+// TorrentState is the client independent state of a torrent.
+type TorrentState string
+
+const (
+	TorrentStateSeeding     TorrentState = "seeding"
+	TorrentStateDownloading TorrentState = "downloading"
+	TorrentStatePaused      TorrentState = "paused"
+	TorrentStateQueued      TorrentState = "queued"
+	// TorrentStateChecking covers all states in which the client is working on the data like checking or moving it.
+	TorrentStateChecking TorrentState = "checking"
+	TorrentStateError    TorrentState = "error"
+	TorrentStateUnknown  TorrentState = "unknown"
+)
+
 type TorrentFile struct {
 	Path string
 	Size int64
 }
 
 type TorrentEntry struct {
-	Client   string
-	Id       string
-	Name     string
-	Ratio    float64
-	Added    time.Time
-	Trackers []string
-	Files    []*TorrentFile
+	Client string
+	Id     string
+	Name   string
+	Ratio  float64
+	State  TorrentState
+	// Added is the completion time of the torrent. It is zero if the torrent has not been completed yet.
+	Added time.Time
+	// SeedingTime is the time the torrent has actively been seeding which excludes paused or stopped periods if the
+	// client tracks them. It is -1 if unknown.
+	SeedingTime time.Duration
+	// SeedingTimeEstimated is set if the client does not track the seeding time and SeedingTime is derived from
+	// timestamps instead, including stopped periods.
+	SeedingTimeEstimated bool
+	Trackers             []string
+	// TrackerStatus is the last message reported by the tracker, e.g. "Unregistered torrent". It is empty if the client
+	// does not report one.
+	TrackerStatus string
+	// Labels are the labels, categories or tags assigned to the torrent in the client.
+	Labels []string
+	// DownloadDir is the absolute directory on the client host the paths of Files are relative to.
+	DownloadDir string
+	Files       []*TorrentFile
 }
 
 func (t TorrentEntry) String() string {
@@ -29,12 +90,20 @@ func (t TorrentEntry) String() string {
 
 type TorrentSourceManager interface {
 	CachedManager
-	GetTorrents() ([]*TorrentEntry, error)
-	DeleteTorrent(client string, id string) error
+	GetTorrents(ctx context.Context) ([]*TorrentEntry, error)
+	DeleteTorrent(ctx context.Context, client string, id string, mode RemovalMode) error
+	// SupportsRemovalMode reports whether the given client supports the mode. It is false for unknown clients.
+	SupportsRemovalMode(client string, mode RemovalMode) bool
 }
 
 type TorrentSource interface {
-	GetTorrentEntries() ([]*TorrentEntry, error)
-	DeleteTorrent(id string) error
+	GetTorrentEntries(ctx context.Context) ([]*TorrentEntry, error)
+	// DeleteTorrent removes or pauses the torrent depending on the given mode. It returns ErrTorrentNotFound for unknown
+	// torrents and ErrRemovalModeNotSupported if the client does not support the mode.
+	DeleteTorrent(ctx context.Context, id string, mode RemovalMode) error
+	// SupportsRemovalMode reports whether DeleteTorrent supports the mode, so that callers deleting multiple torrents can
+	// reject the mode before changing any of them.
+	SupportsRemovalMode(mode RemovalMode) bool
+	// Name returns the id of the configured client instance which is also used as TorrentEntry.Client.
 	Name() string
 }
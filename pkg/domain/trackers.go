@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -8,4 +11,51 @@ type Tracker struct {
 	Name     string
 	MinRatio float64
 	MinAge   time.Duration
+	// MinSeedTime is the required seeding time of a torrent. It is disabled if 0.
+	MinSeedTime time.Duration
+	// Keep protects torrents from deletion regardless of their ratio and age.
+	Keep bool
+}
+
+const redactedValue = "REDACTED"
+
+// passkeyPattern matches path segments of announce urls which look like passkeys or auth tokens.
+var passkeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,}$`)
+
+// NormalizeTrackerUrl returns the announce url without credentials. Query values, user info and path segments looking
+// like passkeys are redacted, so the url can be logged and matched against tracker patterns. Values which are no urls,
+// e.g. plain hosts, are returned lower-cased.
+func NormalizeTrackerUrl(announceUrl string) string {
+	announceUrl = strings.TrimSpace(announceUrl)
+	parsedUrl, err := url.Parse(announceUrl)
+	if err != nil || parsedUrl.Host == "" {
+		return strings.ToLower(announceUrl)
+	}
+	parsedUrl.Scheme = strings.ToLower(parsedUrl.Scheme)
+	parsedUrl.Host = strings.ToLower(parsedUrl.Host)
+	parsedUrl.User = nil
+	parsedUrl.Fragment = ""
+	segments := strings.Split(parsedUrl.Path, "/")
+	for i, segment := range segments {
+		if passkeyPattern.MatchString(segment) {
+			segments[i] = redactedValue
+		}
+	}
+	parsedUrl.Path = strings.Join(segments, "/")
+	parsedUrl.RawPath = ""
+	query := parsedUrl.Query()
+	for key := range query {
+		query.Set(key, redactedValue)
+	}
+	parsedUrl.RawQuery = query.Encode()
+	return parsedUrl.String()
+}
+
+// GetTrackerHost returns the host of the given normalized announce url without port. Plain hosts are returned as is.
+func GetTrackerHost(tracker string) string {
+	parsedUrl, err := url.Parse(tracker)
+	if err != nil || parsedUrl.Host == "" {
+		return tracker
+	}
+	return parsedUrl.Hostname()
 }
@@ -0,0 +1,146 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+)
+
+var _ inventory.FilesystemInspector = (*Inspector)(nil)
+
+var ErrPathNotMapped = errors.New("path is not covered by any path mapping")
+var ErrPathNotInScanRoot = errors.New("path is not within any scan root")
+
+// PathMapping translates paths as reported by the *arr instances and torrent clients to paths on the local host, e.g.
+// if the seedbox storage is mounted to a different directory.
+type PathMapping struct {
+	Remote string
+	Local  string
+}
+
+type Inspector struct {
+	pathMappings []PathMapping
+	// scanRoots are the remote directories which are scanned for orphaned files
+	scanRoots []string
+	dryRun    bool
+}
+
+// NewInspector creates an inspector that only inspects paths covered by the given mappings. Without mappings, paths are
+// used as is.
+func NewInspector(pathMappings []PathMapping, scanRoots []string, dryRun bool) *Inspector {
+	cleanedMappings := make([]PathMapping, 0, len(pathMappings))
+	for _, pathMapping := range pathMappings {
+		cleanedMappings = append(cleanedMappings, PathMapping{
+			Remote: filepath.Clean(pathMapping.Remote),
+			Local:  filepath.Clean(pathMapping.Local),
+		})
+	}
+	cleanedScanRoots := make([]string, 0, len(scanRoots))
+	for _, scanRoot := range scanRoots {
+		cleanedScanRoots = append(cleanedScanRoots, filepath.Clean(scanRoot))
+	}
+	return &Inspector{pathMappings: cleanedMappings, scanRoots: cleanedScanRoots, dryRun: dryRun}
+}
+
+func (inspector *Inspector) Stat(remotePath string) (inventory.FileInfo, error) {
+	localPath, err := inspector.getLocalPath(remotePath)
+	if err != nil {
+		return inventory.FileInfo{}, err
+	}
+	fileInfo, err := stat(localPath)
+	if err != nil {
+		return inventory.FileInfo{}, fmt.Errorf("could not stat %q: %w", localPath, err)
+	}
+	return fileInfo, nil
+}
+
+func (inspector *Inspector) ListFiles() ([]inventory.ListedFile, error) {
+	listedFiles := make([]inventory.ListedFile, 0)
+	for _, scanRoot := range inspector.scanRoots {
+		localRoot, err := inspector.getLocalPath(scanRoot)
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.WalkDir(localRoot, func(localPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			relativePath, err := filepath.Rel(localRoot, localPath)
+			if err != nil {
+				return err
+			}
+			fileInfo, err := stat(localPath)
+			if err != nil {
+				return fmt.Errorf("could not stat %q: %w", localPath, err)
+			}
+			listedFiles = append(listedFiles, inventory.ListedFile{
+				Path:     filepath.Join(scanRoot, relativePath),
+				FileInfo: fileInfo,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not scan %q: %w", scanRoot, err)
+		}
+	}
+	return listedFiles, nil
+}
+
+func (inspector *Inspector) DeleteFile(remotePath string) error {
+	remotePath = filepath.Clean(remotePath)
+	if !slices.ContainsFunc(inspector.scanRoots, func(scanRoot string) bool {
+		return remotePath != scanRoot && isWithin(remotePath, scanRoot)
+	}) {
+		return fmt.Errorf("%w: %q", ErrPathNotInScanRoot, remotePath)
+	}
+	localPath, err := inspector.getLocalPath(remotePath)
+	if err != nil {
+		return err
+	}
+	if inspector.dryRun {
+		slog.Info("[DRY RUN] Skipping file deletion.", "path", localPath)
+		return nil
+	}
+	if err = os.Remove(localPath); err != nil {
+		return fmt.Errorf("could not delete %q: %w", localPath, err)
+	}
+	return nil
+}
+
+// getLocalPath returns the local path of the given remote path using the most specific path mapping.
+func (inspector *Inspector) getLocalPath(remotePath string) (string, error) {
+	remotePath = filepath.Clean(remotePath)
+	if len(inspector.pathMappings) == 0 {
+		return remotePath, nil
+	}
+	var bestMapping *PathMapping
+	for i, pathMapping := range inspector.pathMappings {
+		if !isWithin(remotePath, pathMapping.Remote) {
+			continue
+		}
+		if bestMapping == nil || len(pathMapping.Remote) > len(bestMapping.Remote) {
+			bestMapping = &inspector.pathMappings[i]
+		}
+	}
+	if bestMapping == nil {
+		return "", fmt.Errorf("%w: %q", ErrPathNotMapped, remotePath)
+	}
+	return filepath.Join(bestMapping.Local, strings.TrimPrefix(remotePath, bestMapping.Remote)), nil
+}
+
+func isWithin(path, directory string) bool {
+	if path == directory || directory == string(filepath.Separator) {
+		return true
+	}
+	return strings.HasPrefix(path, directory+string(filepath.Separator))
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package filesystem
+
+import (
+	"errors"
+
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+)
+
+func stat(path string) (inventory.FileInfo, error) {
+	return inventory.FileInfo{}, errors.New("hardlink detection is not supported on this platform")
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+)
+
+func stat(path string) (inventory.FileInfo, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return inventory.FileInfo{}, err
+	}
+	sysStat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return inventory.FileInfo{}, fmt.Errorf("unexpected stat type %T", fileInfo.Sys())
+	}
+	return inventory.FileInfo{
+		FileIdentity: inventory.FileIdentity{
+			Device: uint64(sysStat.Dev),
+			Inode:  uint64(sysStat.Ino),
+		},
+		Links:   uint64(sysStat.Nlink),
+		Size:    fileInfo.Size(),
+		ModTime: fileInfo.ModTime(),
+	}, nil
+}
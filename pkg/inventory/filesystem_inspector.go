@@ -0,0 +1,35 @@
+package inventory
+
+import "time"
+
+// FileIdentity identifies the data of a file independent of the paths it is hardlinked to.
+type FileIdentity struct {
+	Device uint64
+	Inode  uint64
+}
+
+type FileInfo struct {
+	FileIdentity
+	// Links is the number of hardlinks pointing to the data.
+	Links   uint64
+	Size    int64
+	ModTime time.Time
+}
+
+// ListedFile is a regular file found while scanning the configured roots.
+type ListedFile struct {
+	// Path is the path as seen by the *arr instances and torrent clients.
+	Path string
+	FileInfo
+}
+
+type FilesystemInspector interface {
+	// Stat returns the on-disk information of the file at the given path as seen by the *arr instance or torrent client.
+	Stat(remotePath string) (FileInfo, error)
+
+	// ListFiles returns all regular files below the configured scan roots.
+	ListFiles() ([]ListedFile, error)
+
+	// DeleteFile removes the file at the given path. Only files below the configured scan roots may be deleted.
+	DeleteFile(remotePath string) error
+}
@@ -13,5 +13,7 @@ type LinkedMedia struct {
 
 type LinkedMediaFile struct {
 	domain.MediaFile
-	TorrentEntry *domain.TorrentEntry
+	// TorrentEntries contains every torrent seeding the file, e.g. when it is cross-seeded on multiple trackers. It is
+	// empty if no torrent could be linked.
+	TorrentEntries []*domain.TorrentEntry
 }
@@ -2,6 +2,7 @@ package inventory
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,11 +12,17 @@ import (
 
 type mediaId struct {
 	MediaType domain.MediaType
+	Instance  string
 	Id        int64
 	FileId    int64
 	Season    int
 }
 
+// matchesMedia reports whether the id references the given media of the given *arr instance.
+func (m mediaId) matchesMedia(metadata domain.MediaMetadata) bool {
+	return m.MediaType == metadata.Type && m.Instance == metadata.Instance && m.Id == metadata.Id
+}
+
 func (m mediaId) getMatchingLinkedMediaIndexes(linkedMediaFiles []LinkedMediaFile) []int {
 	indexes := make([]int, 0)
 	for i, linkedMediaFile := range linkedMediaFiles {
@@ -36,7 +43,7 @@ func (m mediaId) getMatchingLinkedMediaIndexes(linkedMediaFiles []LinkedMediaFil
 }
 
 func (m mediaId) String() string {
-	idStr := fmt.Sprintf("%s-%d", m.MediaType, m.Id)
+	idStr := fmt.Sprintf("%s-%s-%d", m.MediaType, m.Instance, m.Id)
 	if m.FileId != 0 {
 		return fmt.Sprintf("%s-%d", idStr, m.FileId)
 	} else if m.Season != 0 {
@@ -47,34 +54,42 @@ func (m mediaId) String() string {
 
 func parseMediaId(rawId string) (mediaId, error) {
 	idSplit := strings.Split(rawId, "-")
+	if len(idSplit) < 3 || len(idSplit) > 5 {
+		return mediaId{}, webserver.ErrMalformedMediaId
+	}
 	mediaType := idSplit[0]
-	if mediaType != "movie" && mediaType != "series" {
+	if !slices.Contains([]string{"movie", "series", "music", "book"}, mediaType) {
+		return mediaId{}, webserver.ErrMalformedMediaId
+	}
+	instance := idSplit[1]
+	if instance == "" {
 		return mediaId{}, webserver.ErrMalformedMediaId
 	}
-	id, err := strconv.ParseInt(idSplit[1], 10, 64)
+	id, err := strconv.ParseInt(idSplit[2], 10, 64)
 	if err != nil {
 		return mediaId{}, webserver.ErrMalformedMediaId
 	}
 	var fileId int64
 	var season int
-	if len(idSplit) == 3 {
-		// movie-10-8
-		fileId, err = strconv.ParseInt(idSplit[2], 10, 64)
+	if len(idSplit) == 4 {
+		// movie-radarr-10-8
+		fileId, err = strconv.ParseInt(idSplit[3], 10, 64)
 		if err != nil {
 			return mediaId{}, webserver.ErrMalformedMediaId
 		}
-	} else if len(idSplit) == 4 {
-		// series-1337-s-2
-		if idSplit[2] != "s" {
+	} else if len(idSplit) == 5 {
+		// series-sonarr-1337-s-2 or music-lidarr-42-s-7 with the album or book id in place of the season
+		if idSplit[3] != "s" {
 			return mediaId{}, webserver.ErrMalformedMediaId
 		}
-		season, err = strconv.Atoi(idSplit[3])
+		season, err = strconv.Atoi(idSplit[4])
 		if err != nil {
 			return mediaId{}, webserver.ErrMalformedMediaId
 		}
 	}
 	return mediaId{
 		MediaType: domain.MediaType(mediaType),
+		Instance:  instance,
 		Id:        id,
 		FileId:    fileId,
 		Season:    season,
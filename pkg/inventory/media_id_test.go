@@ -26,10 +26,11 @@ func Test_parseMediaId(t *testing.T) {
 		{
 			name: "movie id",
 			args: args{
-				rawId: "movie-1337",
+				rawId: "movie-radarr-1337",
 			},
 			want: mediaId{
 				MediaType: domain.MediaTypeMovie,
+				Instance:  "radarr",
 				Id:        1337,
 			},
 			wantErr: require.NoError,
@@ -37,10 +38,11 @@ func Test_parseMediaId(t *testing.T) {
 		{
 			name: "series id",
 			args: args{
-				rawId: "series-10",
+				rawId: "series-sonarr-10",
 			},
 			want: mediaId{
 				MediaType: domain.MediaTypeSeries,
+				Instance:  "sonarr",
 				Id:        10,
 			},
 			wantErr: require.NoError,
@@ -48,24 +50,25 @@ func Test_parseMediaId(t *testing.T) {
 		{
 			name: "error on invalid media type",
 			args: args{
-				rawId: "film-1337",
+				rawId: "film-radarr-1337",
 			},
 			wantErr: wantErrMalformedMediaId,
 		},
 		{
 			name: "error on invalid id",
 			args: args{
-				rawId: "movie-10a",
+				rawId: "movie-radarr-10a",
 			},
 			wantErr: wantErrMalformedMediaId,
 		},
 		{
 			name: "specific file id of movie",
 			args: args{
-				rawId: "movie-10-7",
+				rawId: "movie-radarr-10-7",
 			},
 			want: mediaId{
 				MediaType: domain.MediaTypeMovie,
+				Instance:  "radarr",
 				Id:        10,
 				FileId:    7,
 			},
@@ -74,33 +77,74 @@ func Test_parseMediaId(t *testing.T) {
 		{
 			name: "error on invalid file id",
 			args: args{
-				rawId: "movie-10-7a",
+				rawId: "movie-radarr-10-7a",
 			},
 			wantErr: wantErrMalformedMediaId,
 		},
 		{
 			name: "specific season of series",
 			args: args{
-				rawId: "series-1337-s-2",
+				rawId: "series-sonarr-1337-s-2",
 			},
 			want: mediaId{
 				MediaType: domain.MediaTypeSeries,
+				Instance:  "sonarr",
 				Id:        1337,
 				Season:    2,
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "specific album of music",
+			args: args{
+				rawId: "music-lidarr-42-s-7",
+			},
+			want: mediaId{
+				MediaType: domain.MediaTypeMusic,
+				Instance:  "lidarr",
+				Id:        42,
+				Season:    7,
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "specific book of author",
+			args: args{
+				rawId: "book-readarr-5-s-12",
+			},
+			want: mediaId{
+				MediaType: domain.MediaTypeBook,
+				Instance:  "readarr",
+				Id:        5,
+				Season:    12,
+			},
+			wantErr: require.NoError,
+		},
 		{
 			name: "error on invalid season",
 			args: args{
-				rawId: "series-1337-s-2a",
+				rawId: "series-sonarr-1337-s-2a",
+			},
+			wantErr: wantErrMalformedMediaId,
+		},
+		{
+			name: "error on missing instance",
+			args: args{
+				rawId: "movie-1337",
+			},
+			wantErr: wantErrMalformedMediaId,
+		},
+		{
+			name: "error on empty instance",
+			args: args{
+				rawId: "movie--1337",
 			},
 			wantErr: wantErrMalformedMediaId,
 		},
 		{
 			name: "error on invalid season prefix",
 			args: args{
-				rawId: "series-1337-a-2",
+				rawId: "series-sonarr-1337-a-2",
 			},
 			wantErr: wantErrMalformedMediaId,
 		},
@@ -117,6 +161,7 @@ func Test_parseMediaId(t *testing.T) {
 func Test_mediaId_String(t *testing.T) {
 	type fields struct {
 		MediaType domain.MediaType
+		Instance  string
 		Id        int64
 		FileId    int64
 		Season    int
@@ -130,33 +175,37 @@ func Test_mediaId_String(t *testing.T) {
 			name: "generate basic id",
 			fields: fields{
 				MediaType: domain.MediaTypeMovie,
+				Instance:  "radarr",
 				Id:        1337,
 			},
-			want: "movie-1337",
+			want: "movie-radarr-1337",
 		},
 		{
 			name: "generate file id",
 			fields: fields{
 				MediaType: domain.MediaTypeSeries,
+				Instance:  "sonarr",
 				Id:        10,
 				FileId:    7,
 			},
-			want: "series-10-7",
+			want: "series-sonarr-10-7",
 		},
 		{
 			name: "generate season id",
 			fields: fields{
 				MediaType: domain.MediaTypeSeries,
+				Instance:  "sonarr",
 				Id:        10,
 				Season:    2,
 			},
-			want: "series-10-s-2",
+			want: "series-sonarr-10-s-2",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := mediaId{
 				MediaType: tt.fields.MediaType,
+				Instance:  tt.fields.Instance,
 				Id:        tt.fields.Id,
 				FileId:    tt.fields.FileId,
 				Season:    tt.fields.Season,
@@ -188,6 +237,7 @@ func Test_mediaId_getMatchingLinkedMediaIndexes(t *testing.T) {
 	}
 	type fields struct {
 		MediaType domain.MediaType
+		Instance  string
 		Id        int64
 		FileId    int64
 		Season    int
@@ -205,6 +255,7 @@ func Test_mediaId_getMatchingLinkedMediaIndexes(t *testing.T) {
 			name: "whole entry",
 			fields: fields{
 				MediaType: domain.MediaTypeMovie,
+				Instance:  "radarr",
 				Id:        1337,
 			},
 			args: args{
@@ -222,6 +273,7 @@ func Test_mediaId_getMatchingLinkedMediaIndexes(t *testing.T) {
 			name: "specific file id",
 			fields: fields{
 				MediaType: domain.MediaTypeMovie,
+				Instance:  "radarr",
 				Id:        1337,
 				FileId:    2,
 			},
@@ -240,6 +292,7 @@ func Test_mediaId_getMatchingLinkedMediaIndexes(t *testing.T) {
 			name: "specific season",
 			fields: fields{
 				MediaType: domain.MediaTypeSeries,
+				Instance:  "sonarr",
 				Id:        1337,
 				Season:    1,
 			},
@@ -260,6 +313,7 @@ func Test_mediaId_getMatchingLinkedMediaIndexes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m := mediaId{
 				MediaType: tt.fields.MediaType,
+				Instance:  tt.fields.Instance,
 				Id:        tt.fields.Id,
 				FileId:    tt.fields.FileId,
 				Season:    tt.fields.Season,
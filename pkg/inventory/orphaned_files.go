@@ -0,0 +1,156 @@
+package inventory
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/almanac1631/scrubarr/internal/app/webserver"
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+type enrichedOrphanedFile struct {
+	id              string
+	file            ListedFile
+	reclaimableSize int64
+}
+
+func getOrphanedFileId(filePath string) string {
+	hash := sha1.Sum([]byte(filePath))
+	return "file-" + hex.EncodeToString(hash[:])
+}
+
+// findOrphanedFiles returns all files below the scan roots which neither belong to a media file nor to a torrent. Files
+// sharing their data with a known file via a hardlink are not orphaned either.
+func findOrphanedFiles(calculator *reclaimableSizeCalculator, media []*domain.MediaEntry, torrents []*domain.TorrentEntry) ([]enrichedOrphanedFile, error) {
+	listedFiles, err := calculator.inspector.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+	knownPaths := make([]string, 0)
+	for _, mediaEntry := range media {
+		for _, file := range mediaEntry.Files {
+			knownPaths = append(knownPaths, file.Path)
+		}
+	}
+	for _, torrentEntry := range torrents {
+		knownPaths = append(knownPaths, getTorrentEntryPaths(torrentEntry)...)
+	}
+	knownPathSet := make(map[string]struct{}, len(knownPaths))
+	knownIdentities := make(map[FileIdentity]struct{}, len(knownPaths))
+	for _, knownPath := range knownPaths {
+		knownPathSet[path.Clean(knownPath)] = struct{}{}
+		if fileInfo := calculator.stat(knownPath); fileInfo != nil {
+			knownIdentities[fileInfo.FileIdentity] = struct{}{}
+		}
+	}
+	orphanedFiles := make([]enrichedOrphanedFile, 0)
+	for _, listedFile := range listedFiles {
+		if _, ok := knownPathSet[path.Clean(listedFile.Path)]; ok {
+			continue
+		}
+		if _, ok := knownIdentities[listedFile.FileIdentity]; ok {
+			continue
+		}
+		reclaimableSize := int64(0)
+		if listedFile.Links <= 1 {
+			reclaimableSize = listedFile.Size
+		}
+		orphanedFiles = append(orphanedFiles, enrichedOrphanedFile{
+			id:              getOrphanedFileId(listedFile.Path),
+			file:            listedFile,
+			reclaimableSize: reclaimableSize,
+		})
+	}
+	return orphanedFiles, nil
+}
+
+func (s *Service) GetOrphanedFiles(ctx context.Context, page int, sortInfo webserver.SortInfo) (rows []webserver.OrphanedFileRow, hasNext bool, err error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.enrichedLinkedMediaCache == nil {
+		if err := s.RefreshCache(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	all := slices.Clone(s.orphanedFilesCache)
+	slices.SortFunc(all, func(a, b enrichedOrphanedFile) int {
+		var result int
+		switch sortInfo.Key {
+		case webserver.SortKeyName:
+			result = strings.Compare(strings.ToLower(a.file.Path), strings.ToLower(b.file.Path))
+		case webserver.SortKeySize:
+			result = cmp.Compare(a.file.Size, b.file.Size)
+		case webserver.SortKeyAdded:
+			result = a.file.ModTime.Compare(b.file.ModTime)
+		default:
+			slog.Error("Received unknown sort key.", "sortKey", sortInfo.Key)
+			result = 0
+		}
+		if sortInfo.Order == webserver.SortOrderDesc {
+			result = -result
+		}
+		return result
+	})
+	start := pageSize * (page - 1)
+	if start >= len(all) {
+		return []webserver.OrphanedFileRow{}, false, nil
+	}
+	end := start + pageSize
+	if end < len(all) {
+		hasNext = true
+	} else {
+		end = len(all)
+	}
+	currentTime := now()
+	for _, e := range all[start:end] {
+		rows = append(rows, webserver.OrphanedFileRow{
+			Id:              e.id,
+			Path:            e.file.Path,
+			Size:            e.file.Size,
+			ReclaimableSize: e.reclaimableSize,
+			Modified:        e.file.ModTime,
+			Age:             currentTime.Sub(e.file.ModTime),
+			AllowDeletion:   !s.mediaSourceDegraded && !s.torrentSourceDegraded,
+		})
+	}
+	return rows, hasNext, nil
+}
+
+func (s *Service) DeleteOrphanedFile(id string) error {
+	s.Lock()
+	defer s.Unlock()
+	// files of entries on a degraded instance would wrongly show up as orphaned
+	if s.mediaSourceDegraded || s.torrentSourceDegraded {
+		return fmt.Errorf("refusing to delete orphaned file %q while a source is degraded", id)
+	}
+	entryIndex := slices.IndexFunc(s.orphanedFilesCache, func(e enrichedOrphanedFile) bool {
+		return e.id == id
+	})
+	if entryIndex == -1 {
+		return webserver.ErrMediaNotFound
+	}
+	entry := s.orphanedFilesCache[entryIndex]
+
+	// the file might have been replaced or picked up by a torrent client since the last scan
+	fileInfo, err := s.filesystemInspector.Stat(entry.file.Path)
+	if err != nil {
+		return fmt.Errorf("could not inspect orphaned file %q: %w", entry.file.Path, err)
+	}
+	if fileInfo.FileIdentity != entry.file.FileIdentity || fileInfo.Size != entry.file.Size || !fileInfo.ModTime.Equal(entry.file.ModTime) {
+		return fmt.Errorf("refusing to delete orphaned file %q as it changed since the last scan", entry.file.Path)
+	}
+
+	if err = s.filesystemInspector.DeleteFile(entry.file.Path); err != nil {
+		return fmt.Errorf("could not delete orphaned file %q: %w", entry.file.Path, err)
+	}
+
+	s.orphanedFilesCache = append(s.orphanedFilesCache[:entryIndex], s.orphanedFilesCache[entryIndex+1:]...)
+	return nil
+}
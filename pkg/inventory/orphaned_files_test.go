@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+func TestFindOrphanedFiles(t *testing.T) {
+	inspector := mockFilesystemInspector{
+		"/media/movie.mkv":             {FileIdentity: FileIdentity{Device: 1, Inode: 100}, Links: 2, Size: 1000},
+		"/downloads/movie/movie.mkv":   {FileIdentity: FileIdentity{Device: 1, Inode: 100}, Links: 2, Size: 1000},
+		"/downloads/movie/sample.mkv":  {FileIdentity: FileIdentity{Device: 1, Inode: 101}, Links: 1, Size: 20},
+		"/downloads/leftover.mkv":      {FileIdentity: FileIdentity{Device: 1, Inode: 200}, Links: 1, Size: 500},
+		"/downloads/leftover-copy.mkv": {FileIdentity: FileIdentity{Device: 1, Inode: 300}, Links: 2, Size: 300},
+		"/downloads/other/movie.mkv":   {FileIdentity: FileIdentity{Device: 1, Inode: 100}, Links: 2, Size: 1000},
+	}
+	media := []*domain.MediaEntry{{
+		Files: []domain.MediaFile{{Path: "/media/movie.mkv"}},
+	}}
+	torrents := []*domain.TorrentEntry{{
+		DownloadDir: "/downloads",
+		Files: []*domain.TorrentFile{
+			{Path: "movie/movie.mkv"},
+			{Path: "movie/sample.mkv"},
+		},
+	}}
+	want := []enrichedOrphanedFile{
+		{
+			id:              getOrphanedFileId("/downloads/leftover-copy.mkv"),
+			file:            ListedFile{Path: "/downloads/leftover-copy.mkv", FileInfo: inspector["/downloads/leftover-copy.mkv"]},
+			reclaimableSize: 0,
+		},
+		{
+			id:              getOrphanedFileId("/downloads/leftover.mkv"),
+			file:            ListedFile{Path: "/downloads/leftover.mkv", FileInfo: inspector["/downloads/leftover.mkv"]},
+			reclaimableSize: 500,
+		},
+	}
+	got, err := findOrphanedFiles(newReclaimableSizeCalculator(inspector), media, torrents)
+	if err != nil {
+		t.Fatalf("findOrphanedFiles() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findOrphanedFiles() got = %v, want %v", got, want)
+	}
+}
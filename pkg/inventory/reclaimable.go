@@ -0,0 +1,83 @@
+package inventory
+
+import (
+	"log/slog"
+	"path"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+// reclaimableSizeCalculator determines how many bytes are actually freed when deleting a set of files. Data that is
+// hardlinked to paths outside the set stays on disk and does not count.
+type reclaimableSizeCalculator struct {
+	inspector FilesystemInspector
+	// fileInfos caches stat results per path while nil values mark paths that could not be inspected
+	fileInfos map[string]*FileInfo
+}
+
+func newReclaimableSizeCalculator(inspector FilesystemInspector) *reclaimableSizeCalculator {
+	return &reclaimableSizeCalculator{inspector, make(map[string]*FileInfo)}
+}
+
+func (c *reclaimableSizeCalculator) stat(filePath string) *FileInfo {
+	if fileInfo, ok := c.fileInfos[filePath]; ok {
+		return fileInfo
+	}
+	fileInfo, err := c.inspector.Stat(filePath)
+	if err != nil {
+		slog.Debug("Could not inspect file for reclaimable size.", "path", filePath, "error", err)
+		c.fileInfos[filePath] = nil
+		return nil
+	}
+	c.fileInfos[filePath] = &fileInfo
+	return &fileInfo
+}
+
+// getReclaimableSize returns the number of bytes freed by deleting all given paths or -1 if any of them could not be
+// inspected.
+func (c *reclaimableSizeCalculator) getReclaimableSize(filePaths []string) int64 {
+	type identityUsage struct {
+		fileInfo *FileInfo
+		paths    map[string]struct{}
+	}
+	usages := make(map[FileIdentity]*identityUsage)
+	for _, filePath := range filePaths {
+		fileInfo := c.stat(filePath)
+		if fileInfo == nil {
+			return -1
+		}
+		usage, ok := usages[fileInfo.FileIdentity]
+		if !ok {
+			usage = &identityUsage{fileInfo, make(map[string]struct{})}
+			usages[fileInfo.FileIdentity] = usage
+		}
+		usage.paths[filePath] = struct{}{}
+	}
+	reclaimableSize := int64(0)
+	for _, usage := range usages {
+		if uint64(len(usage.paths)) >= usage.fileInfo.Links {
+			reclaimableSize += usage.fileInfo.Size
+		}
+	}
+	return reclaimableSize
+}
+
+// getLinkedMediaPaths returns the paths removed when deleting the linked media including all files of its torrents.
+func getLinkedMediaPaths(linkedMedia LinkedMedia) []string {
+	filePaths := make([]string, 0)
+	for _, file := range linkedMedia.Files {
+		filePaths = append(filePaths, file.Path)
+		for _, torrentEntry := range file.TorrentEntries {
+			filePaths = append(filePaths, getTorrentEntryPaths(torrentEntry)...)
+		}
+	}
+	return filePaths
+}
+
+func getTorrentEntryPaths(torrentEntry *domain.TorrentEntry) []string {
+	filePaths := make([]string, 0, len(torrentEntry.Files))
+	for _, file := range torrentEntry.Files {
+		filePaths = append(filePaths, path.Join(torrentEntry.DownloadDir, file.Path))
+	}
+	return filePaths
+}
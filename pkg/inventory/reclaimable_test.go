@@ -0,0 +1,60 @@
+package inventory
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+type mockFilesystemInspector map[string]FileInfo
+
+func (m mockFilesystemInspector) Stat(remotePath string) (FileInfo, error) {
+	fileInfo, ok := m[remotePath]
+	if !ok {
+		return FileInfo{}, errors.New("file not found")
+	}
+	return fileInfo, nil
+}
+
+func (m mockFilesystemInspector) ListFiles() ([]ListedFile, error) {
+	listedFiles := make([]ListedFile, 0, len(m))
+	for _, filePath := range slices.Sorted(maps.Keys(m)) {
+		listedFiles = append(listedFiles, ListedFile{Path: filePath, FileInfo: m[filePath]})
+	}
+	return listedFiles, nil
+}
+
+func (m mockFilesystemInspector) DeleteFile(remotePath string) error {
+	delete(m, remotePath)
+	return nil
+}
+
+func TestReclaimableSizeCalculator_GetReclaimableSize(t *testing.T) {
+	inspector := mockFilesystemInspector{
+		"/media/movie.mkv":      {FileIdentity: FileIdentity{Device: 1, Inode: 100}, Links: 2, Size: 1000},
+		"/downloads/movie.mkv":  {FileIdentity: FileIdentity{Device: 1, Inode: 100}, Links: 2, Size: 1000},
+		"/media/series.mkv":     {FileIdentity: FileIdentity{Device: 1, Inode: 200}, Links: 3, Size: 500},
+		"/downloads/series.mkv": {FileIdentity: FileIdentity{Device: 1, Inode: 200}, Links: 3, Size: 500},
+		"/downloads/sample.mkv": {FileIdentity: FileIdentity{Device: 1, Inode: 300}, Links: 1, Size: 20},
+	}
+	tests := []struct {
+		name      string
+		filePaths []string
+		want      int64
+	}{
+		{"all hardlinks deleted", []string{"/media/movie.mkv", "/downloads/movie.mkv"}, 1000},
+		{"hardlink kept", []string{"/media/movie.mkv"}, 0},
+		{"hardlink outside of deletion set", []string{"/media/series.mkv", "/downloads/series.mkv", "/downloads/sample.mkv"}, 20},
+		{"duplicate paths counted once", []string{"/media/movie.mkv", "/media/movie.mkv"}, 0},
+		{"unknown file", []string{"/media/movie.mkv", "/downloads/movie.mkv", "/media/unknown.mkv"}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := newReclaimableSizeCalculator(inspector)
+			if got := calculator.getReclaimableSize(tt.filePaths); got != tt.want {
+				t.Errorf("getReclaimableSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
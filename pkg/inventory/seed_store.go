@@ -0,0 +1,21 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+// SeedObservation is what scrubarr itself observed of a torrent across refreshes and restarts.
+type SeedObservation struct {
+	// SeedingTime is the accumulated time the torrent was seen seeding.
+	SeedingTime time.Duration
+	// Uploaded is the accumulated number of uploaded bytes derived from the ratio changes.
+	Uploaded int64
+}
+
+type SeedStore interface {
+	// Observe records the current state of the given torrents and returns the accumulated observations of them by
+	// domain.TorrentEntry.Client and domain.TorrentEntry.Id.
+	Observe(torrents []*domain.TorrentEntry, at time.Time) (map[[2]string]SeedObservation, error)
+}
@@ -2,12 +2,14 @@ package inventory
 
 import (
 	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +26,7 @@ type enrichedLinkedMedia struct {
 	linkedMedia      LinkedMedia
 	evaluationReport EvaluationReport
 	size             int64
+	reclaimableSize  int64
 	added            time.Time
 }
 
@@ -32,10 +35,11 @@ func (m enrichedLinkedMedia) getScore() int {
 }
 
 type enrichedOrphanedTorrent struct {
-	torrentEntry *domain.TorrentEntry
-	size         int64
-	decision     domain.Decision
-	tracker      *domain.Tracker
+	torrentEntry    *domain.TorrentEntry
+	size            int64
+	reclaimableSize int64
+	decision        domain.Decision
+	tracker         *domain.Tracker
 }
 
 func (e enrichedOrphanedTorrent) getScore() int {
@@ -44,10 +48,12 @@ func (e enrichedOrphanedTorrent) getScore() int {
 
 func decisionScore(d domain.Decision) int {
 	switch d {
-	case domain.DecisionSafeToDelete:
+	case domain.DecisionUnregistered:
 		return 0
-	case domain.DecisionPending:
+	case domain.DecisionSafeToDelete:
 		return 1
+	case domain.DecisionPending:
+		return 2
 	default:
 		return -1
 	}
@@ -58,22 +64,32 @@ type Service struct {
 	useCache, saveCache      bool
 	enrichedLinkedMediaCache []enrichedLinkedMedia
 	orphanedTorrentsCache    []enrichedOrphanedTorrent
+	mediaSourceDegraded      bool
+	torrentSourceDegraded    bool
+	orphanedFilesCache       []enrichedOrphanedFile
 	mediaSourceManager       domain.MediaSourceManager
 	torrentSourceManager     domain.TorrentSourceManager
 	linker                   Linker
 	retentionPolicy          RetentionPolicy
+	// filesystemInspector is optional and enables the hardlink aware reclaimable size calculation and the orphaned file
+	// scan
+	filesystemInspector FilesystemInspector
+	// seedStore is optional and replaces seeding times the clients do not track themselves and ratios lost by re-adding
+	// torrents with observed ones
+	seedStore SeedStore
 }
 
-func NewService(useCache, saveCache bool, mediaSourceManager domain.MediaSourceManager, torrentSourceManager domain.TorrentSourceManager, linker Linker, retentionPolicy RetentionPolicy) *Service {
-	return &Service{RWMutex: &sync.RWMutex{}, useCache: useCache, saveCache: saveCache, mediaSourceManager: mediaSourceManager, torrentSourceManager: torrentSourceManager, linker: linker, retentionPolicy: retentionPolicy}
+func NewService(useCache, saveCache bool, mediaSourceManager domain.MediaSourceManager, torrentSourceManager domain.TorrentSourceManager, linker Linker, retentionPolicy RetentionPolicy, filesystemInspector FilesystemInspector, seedStore SeedStore) *Service {
+	return &Service{RWMutex: &sync.RWMutex{}, useCache: useCache, saveCache: saveCache, mediaSourceManager: mediaSourceManager, torrentSourceManager: torrentSourceManager, linker: linker, retentionPolicy: retentionPolicy, filesystemInspector: filesystemInspector, seedStore: seedStore}
 }
 
 func getAdded(linkedMedia LinkedMedia) time.Time {
 	added := linkedMedia.Added
 	for _, file := range linkedMedia.Files {
-		torrentEntry := file.TorrentEntry
-		if torrentEntry != nil && !torrentEntry.Added.IsZero() && torrentEntry.Added.After(added) {
-			added = torrentEntry.Added
+		for _, torrentEntry := range file.TorrentEntries {
+			if !torrentEntry.Added.IsZero() && torrentEntry.Added.After(added) {
+				added = torrentEntry.Added
+			}
 		}
 	}
 	return added
@@ -87,15 +103,17 @@ func getSize(linkedMedia LinkedMedia) int64 {
 	return size
 }
 
-func (s *Service) GetMediaInventory(page int, sortInfo webserver.SortInfo) (mediaRows []webserver.MediaRow, hasNext bool, err error) {
+func (s *Service) GetMediaInventory(ctx context.Context, page int, sortInfo webserver.SortInfo, filter webserver.MediaFilter) (mediaRows []webserver.MediaRow, hasNext bool, err error) {
 	s.RLock()
 	defer s.RUnlock()
 	if s.enrichedLinkedMediaCache == nil {
-		if err := s.RefreshCache(); err != nil {
+		if err := s.RefreshCache(ctx); err != nil {
 			return nil, false, err
 		}
 	}
-	enrichedLinkedMediaList := slices.Clone(s.enrichedLinkedMediaCache)
+	enrichedLinkedMediaList := slices.DeleteFunc(slices.Clone(s.enrichedLinkedMediaCache), func(media enrichedLinkedMedia) bool {
+		return !matchesMediaFilter(media.linkedMedia.MediaMetadata, filter)
+	})
 	slices.SortFunc(enrichedLinkedMediaList, func(a, b enrichedLinkedMedia) int {
 		var result int
 		switch sortInfo.Key {
@@ -129,13 +147,56 @@ func (s *Service) GetMediaInventory(page int, sortInfo webserver.SortInfo) (medi
 
 	mediaRows = make([]webserver.MediaRow, len(enrichedLinkedMediaList))
 	for i, media := range enrichedLinkedMediaList {
-		mediaRow := getMediaRow(media)
+		mediaRow := s.getMediaRow(media)
 		mediaRow.ChildMediaRows = []webserver.MediaRow{}
 		mediaRows[i] = mediaRow
 	}
 	return mediaRows, hasNext, nil
 }
 
+// matchesMediaFilter reports whether the media matches all non-empty fields of the filter.
+func matchesMediaFilter(media domain.MediaMetadata, filter webserver.MediaFilter) bool {
+	if filter.Tag != "" && !slices.Contains(media.Tags, filter.Tag) {
+		return false
+	}
+	if filter.QualityProfile != "" && media.QualityProfile != filter.QualityProfile {
+		return false
+	}
+	if filter.RootFolder != "" && media.RootFolder != filter.RootFolder {
+		return false
+	}
+	if filter.Monitored != "" && strconv.FormatBool(media.Monitored) != filter.Monitored {
+		return false
+	}
+	if filter.Ended != "" && strconv.FormatBool(media.Ended) != filter.Ended {
+		return false
+	}
+	return true
+}
+
+func (s *Service) GetMediaFilterOptions() webserver.MediaFilterOptions {
+	s.RLock()
+	defer s.RUnlock()
+	options := webserver.MediaFilterOptions{}
+	for _, media := range s.enrichedLinkedMediaCache {
+		for _, tag := range media.linkedMedia.Tags {
+			if !slices.Contains(options.Tags, tag) {
+				options.Tags = append(options.Tags, tag)
+			}
+		}
+		if profile := media.linkedMedia.QualityProfile; profile != "" && !slices.Contains(options.QualityProfiles, profile) {
+			options.QualityProfiles = append(options.QualityProfiles, profile)
+		}
+		if rootFolder := media.linkedMedia.RootFolder; rootFolder != "" && !slices.Contains(options.RootFolders, rootFolder) {
+			options.RootFolders = append(options.RootFolders, rootFolder)
+		}
+	}
+	slices.Sort(options.Tags)
+	slices.Sort(options.QualityProfiles)
+	slices.Sort(options.RootFolders)
+	return options
+}
+
 func (s *Service) GetExpandedMediaRow(rawId string) (mediaRowExpanded webserver.MediaRow, err error) {
 	s.RLock()
 	defer s.RUnlock()
@@ -144,25 +205,55 @@ func (s *Service) GetExpandedMediaRow(rawId string) (mediaRowExpanded webserver.
 		return mediaRowExpanded, err
 	}
 	for _, mediaIter := range s.enrichedLinkedMediaCache {
-		if mediaIter.linkedMedia.Type != id.MediaType || mediaIter.linkedMedia.Id != id.Id {
+		if !id.matchesMedia(mediaIter.linkedMedia.MediaMetadata) {
 			continue
 		}
-		return getMediaRow(mediaIter), nil
+		return s.getMediaRow(mediaIter), nil
 	}
 	return webserver.MediaRow{}, webserver.ErrMediaNotFound
 }
 
 // getMediaRow combines the raw media row generation and evaluation report apply logic to return an enriched media row.
-func getMediaRow(media enrichedLinkedMedia) webserver.MediaRow {
+// Deletion is not allowed while a torrent source is degraded, since the torrents of the media might be missing.
+func (s *Service) getMediaRow(media enrichedLinkedMedia) webserver.MediaRow {
 	row := generateRawMediaRowFromLinkedMedia(media)
-	return applyEvaluationReport(media, row)
+	row = applyEvaluationReport(media, row)
+	torrentEntries := make([]*domain.TorrentEntry, 0)
+	for _, file := range media.linkedMedia.Files {
+		torrentEntries = append(torrentEntries, file.TorrentEntries...)
+	}
+	row.RemovalModes = s.getSupportedRemovalModes(torrentEntries...)
+	if s.torrentSourceDegraded {
+		row = disallowDeletion(row)
+	}
+	return row
+}
+
+// getSupportedRemovalModes returns the removal modes supported by the clients of all given torrents.
+func (s *Service) getSupportedRemovalModes(torrentEntries ...*domain.TorrentEntry) []domain.RemovalMode {
+	return slices.DeleteFunc(slices.Clone(domain.RemovalModes), func(mode domain.RemovalMode) bool {
+		return slices.ContainsFunc(torrentEntries, func(torrentEntry *domain.TorrentEntry) bool {
+			return !s.torrentSourceManager.SupportsRemovalMode(torrentEntry.Client, mode)
+		})
+	})
+}
+
+// disallowDeletion returns a copy of the row in which neither the row nor any of its child rows can be deleted.
+func disallowDeletion(row webserver.MediaRow) webserver.MediaRow {
+	row.AllowDeletion = false
+	childMediaRows := make([]webserver.MediaRow, len(row.ChildMediaRows))
+	for i, childMediaRow := range row.ChildMediaRows {
+		childMediaRows[i] = disallowDeletion(childMediaRow)
+	}
+	row.ChildMediaRows = childMediaRows
+	return row
 }
 
 // getRawFileBasedMedia parses the given media and returns a webserver.MediaRow with a file list. This function does not
 // respect tracker information, deletion decision or hierarchies like seasons.
 func generateRawMediaRowFromLinkedMedia(media enrichedLinkedMedia) webserver.MediaRow {
 	linkedMedia := media.linkedMedia
-	id := fmt.Sprintf("%s-%d", linkedMedia.Type, linkedMedia.Id)
+	id := mediaId{MediaType: linkedMedia.Type, Instance: linkedMedia.Instance, Id: linkedMedia.Id}.String()
 	var torrentInformation webserver.TorrentInformation
 	childMediaRows := make([]webserver.MediaRow, 0)
 	currentTime := now()
@@ -173,23 +264,33 @@ func generateRawMediaRowFromLinkedMedia(media enrichedLinkedMedia) webserver.Med
 			torrentInformation = fileMediaRow.TorrentInformation
 		} else if i > 0 && torrentInformation != fileMediaRow.TorrentInformation {
 			torrentInformation = webserver.TorrentInformation{
-				LinkStatus: getCombinedTorrentLinkStatus(torrentInformation.LinkStatus, fileMediaRow.TorrentInformation.LinkStatus),
-				Ratio:      -1.0,
-				Age:        time.Duration(-1),
+				LinkStatus:  getCombinedTorrentLinkStatus(torrentInformation.LinkStatus, fileMediaRow.TorrentInformation.LinkStatus),
+				Ratio:       -1.0,
+				Age:         time.Duration(-1),
+				SeedingTime: time.Duration(-1),
 			}
 		}
 		childMediaRows = append(childMediaRows, fileMediaRow)
 	}
+	// movies consist of a single file which is not expanded, so its details are shown in the entry itself
+	var details string
+	if linkedMedia.Type == domain.MediaTypeMovie && len(childMediaRows) == 1 {
+		details = childMediaRows[0].Details
+	}
 	if torrentInformation.LinkStatus != webserver.TorrentLinkPresent {
 		torrentInformation.Ratio = -1.0
 		torrentInformation.Age = time.Duration(-1)
+		torrentInformation.SeedingTime = time.Duration(-1)
 	}
 	return webserver.MediaRow{
 		Id:                 id,
 		Type:               linkedMedia.Type,
 		Title:              linkedMedia.Title,
+		Details:            details,
+		Tags:               linkedMedia.Tags,
 		Url:                linkedMedia.Url,
 		Size:               media.size,
+		ReclaimableSize:    media.reclaimableSize,
 		Added:              media.added,
 		TorrentInformation: torrentInformation,
 		ChildMediaRows:     childMediaRows,
@@ -201,22 +302,43 @@ func generateRawMediaRowFromLinkedMedia(media enrichedLinkedMedia) webserver.Med
 func getRawMediaRowFromFile(currentTime time.Time, id string, file LinkedMediaFile) webserver.MediaRow {
 	fileId := fmt.Sprintf("%s-%d", id, file.Id)
 	fileTorrentInformation := webserver.TorrentInformation{
-		LinkStatus: webserver.TorrentLinkMissing,
-		Ratio:      -1.0,
-		Age:        time.Duration(-1),
+		LinkStatus:  webserver.TorrentLinkMissing,
+		Ratio:       -1.0,
+		Age:         time.Duration(-1),
+		SeedingTime: time.Duration(-1),
 	}
 	var added time.Time
-	if file.TorrentEntry != nil {
-		fileTorrentInformation = webserver.TorrentInformation{
-			LinkStatus: webserver.TorrentLinkPresent,
-			Ratio:      file.TorrentEntry.Ratio,
-			Age:        currentTime.Sub(file.TorrentEntry.Added),
+	labels := make([]string, 0)
+	// with multiple torrents, the lowest ratio, the youngest torrent and the shortest seeding time are the limiting ones
+	for i, torrentEntry := range file.TorrentEntries {
+		if i == 0 || torrentEntry.Ratio < fileTorrentInformation.Ratio {
+			fileTorrentInformation.Ratio = torrentEntry.Ratio
+		}
+		if i == 0 || torrentEntry.Added.After(added) {
+			added = torrentEntry.Added
 		}
-		added = file.TorrentEntry.Added
+		if i == 0 || torrentEntry.SeedingTime < fileTorrentInformation.SeedingTime {
+			fileTorrentInformation.SeedingTime = torrentEntry.SeedingTime
+		}
+		if i == 0 || fileTorrentInformation.State == domain.TorrentStateSeeding {
+			fileTorrentInformation.State = torrentEntry.State
+		}
+		for _, label := range torrentEntry.Labels {
+			if !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	fileTorrentInformation.Labels = strings.Join(labels, ", ")
+	if len(file.TorrentEntries) > 0 {
+		fileTorrentInformation.LinkStatus = webserver.TorrentLinkPresent
+		fileTorrentInformation.Age = getTorrentAge(currentTime, added)
+		fileTorrentInformation.TorrentCount = len(file.TorrentEntries)
 	}
 	fileMediaRow := webserver.MediaRow{
 		Id:                 fileId,
 		Title:              path.Base(file.OriginalFilePath),
+		Details:            getMediaFileDetails(file.MediaFile),
 		Size:               file.Size,
 		Added:              added,
 		TorrentInformation: fileTorrentInformation,
@@ -225,6 +347,34 @@ func getRawMediaRowFromFile(currentTime time.Time, id string, file LinkedMediaFi
 	return fileMediaRow
 }
 
+// getMediaFileDetails returns a short description of the file like "S03E04 – Title · WEBDL-1080p · GROUP · aired
+// 2024-01-02" or an empty string if the source does not report any details.
+func getMediaFileDetails(file domain.MediaFile) string {
+	details := make([]string, 0, 4)
+	if len(file.EpisodeNumbers) > 0 {
+		episode := fmt.Sprintf("S%02dE%02d", file.Season, file.EpisodeNumbers[0])
+		if len(file.EpisodeNumbers) > 1 {
+			episode += fmt.Sprintf("-E%02d", file.EpisodeNumbers[len(file.EpisodeNumbers)-1])
+		}
+		if file.EpisodeTitle != "" {
+			episode += " – " + file.EpisodeTitle
+		}
+		details = append(details, episode)
+	}
+	if file.Quality != "" {
+		details = append(details, file.Quality)
+	} else if file.Resolution > 0 {
+		details = append(details, fmt.Sprintf("%dp", file.Resolution))
+	}
+	if file.ReleaseGroup != "" {
+		details = append(details, file.ReleaseGroup)
+	}
+	if !file.AirDate.IsZero() {
+		details = append(details, "aired "+file.AirDate.Format(time.DateOnly))
+	}
+	return strings.Join(details, " · ")
+}
+
 // applyEvaluationReport takes the media and row params the maps the evaluation report of the media param onto the given
 // row param. This includes applying the season hierarchy, calculating season based attributes and adding the decision
 // derived from the report. For this function to work properly, the row`s child rows and the media`s files have to be in
@@ -257,7 +407,11 @@ func applyEvaluationReport(media enrichedLinkedMedia, row webserver.MediaRow) we
 				seasonRow = mediaRow
 				seasonRow.ChildMediaRows = []webserver.MediaRow{mediaRow}
 				seasonRow.Id = seasonId
+				seasonRow.Details = ""
 				seasonRow.Title = fmt.Sprintf("Season %d", file.Season)
+				if file.SeasonTitle != "" {
+					seasonRow.Title = file.SeasonTitle
+				}
 				seasonReport := media.evaluationReport.Seasons[file.Season]
 				seasonRow.Decision = seasonReport.Decision
 				seasonRow.AllowDeletion = true
@@ -275,10 +429,11 @@ func applyEvaluationReport(media enrichedLinkedMedia, row webserver.MediaRow) we
 						seasonRowTracker = domain.Tracker{}
 					}
 					seasonRow.TorrentInformation = webserver.TorrentInformation{
-						LinkStatus: getCombinedTorrentLinkStatus(seasonRow.TorrentInformation.LinkStatus, mediaRow.TorrentInformation.LinkStatus),
-						Tracker:    seasonRowTracker,
-						Ratio:      -1.0,
-						Age:        time.Duration(-1),
+						LinkStatus:  getCombinedTorrentLinkStatus(seasonRow.TorrentInformation.LinkStatus, mediaRow.TorrentInformation.LinkStatus),
+						Tracker:     seasonRowTracker,
+						Ratio:       -1.0,
+						Age:         time.Duration(-1),
+						SeedingTime: time.Duration(-1),
 					}
 					seasonRow.Added = time.Time{}
 				}
@@ -304,11 +459,11 @@ func applyEvaluationReport(media enrichedLinkedMedia, row webserver.MediaRow) we
 	return row
 }
 
-func (s *Service) GetOrphanedTorrents(page int, sortInfo webserver.SortInfo) (rows []webserver.OrphanedTorrentRow, hasNext bool, err error) {
+func (s *Service) GetOrphanedTorrents(ctx context.Context, page int, sortInfo webserver.SortInfo) (rows []webserver.OrphanedTorrentRow, hasNext bool, err error) {
 	s.RLock()
 	defer s.RUnlock()
 	if s.enrichedLinkedMediaCache == nil {
-		if err := s.RefreshCache(); err != nil {
+		if err := s.RefreshCache(ctx); err != nil {
 			return nil, false, err
 		}
 	}
@@ -345,26 +500,55 @@ func (s *Service) GetOrphanedTorrents(page int, sortInfo webserver.SortInfo) (ro
 	}
 	currentTime := now()
 	for _, e := range all[start:end] {
-		t := e.torrentEntry
-		row := webserver.OrphanedTorrentRow{
-			Id:            url.PathEscape(t.Client + "-" + t.Id),
-			Name:          t.Name,
-			Client:        t.Client,
-			Ratio:         t.Ratio,
-			Added:         t.Added,
-			Age:           currentTime.Sub(t.Added),
-			Size:          e.size,
-			Decision:      e.decision,
-			AllowDeletion: true,
-		}
-		if e.tracker != nil {
-			row.Tracker = *e.tracker
-		}
-		rows = append(rows, row)
+		rows = append(rows, s.getOrphanedTorrentRow(currentTime, e))
 	}
 	return rows, hasNext, nil
 }
 
+func (s *Service) GetOrphanedTorrent(rawId string) (webserver.OrphanedTorrentRow, error) {
+	s.RLock()
+	defer s.RUnlock()
+	for _, e := range s.orphanedTorrentsCache {
+		if e.torrentEntry.Client+"-"+e.torrentEntry.Id == rawId {
+			return s.getOrphanedTorrentRow(now(), e), nil
+		}
+	}
+	return webserver.OrphanedTorrentRow{}, webserver.ErrMediaNotFound
+}
+
+func (s *Service) getOrphanedTorrentRow(currentTime time.Time, e enrichedOrphanedTorrent) webserver.OrphanedTorrentRow {
+	t := e.torrentEntry
+	row := webserver.OrphanedTorrentRow{
+		Id:              url.PathEscape(t.Client + "-" + t.Id),
+		Name:            t.Name,
+		Client:          t.Client,
+		Ratio:           t.Ratio,
+		State:           t.State,
+		Labels:          t.Labels,
+		Added:           t.Added,
+		Age:             getTorrentAge(currentTime, t.Added),
+		SeedingTime:     t.SeedingTime,
+		Size:            e.size,
+		ReclaimableSize: e.reclaimableSize,
+		Decision:        e.decision,
+		TrackerStatus:   t.TrackerStatus,
+		AllowDeletion:   !s.mediaSourceDegraded,
+		RemovalModes:    s.getSupportedRemovalModes(t),
+	}
+	if e.tracker != nil {
+		row.Tracker = *e.tracker
+	}
+	return row
+}
+
+// getTorrentAge returns the time since the given completion time or -1 if the torrent has not been completed yet.
+func getTorrentAge(currentTime time.Time, added time.Time) time.Duration {
+	if added.IsZero() {
+		return time.Duration(-1)
+	}
+	return currentTime.Sub(added)
+}
+
 func getCombinedTorrentLinkStatus(groupStatus, entryStatus webserver.TorrentLinkStatus) webserver.TorrentLinkStatus {
 	if groupStatus == webserver.TorrentLinkMissing &&
 		entryStatus == webserver.TorrentLinkPresent {
@@ -373,16 +557,22 @@ func getCombinedTorrentLinkStatus(groupStatus, entryStatus webserver.TorrentLink
 	return entryStatus
 }
 
-func (s *Service) DeleteMedia(rawId string) error {
+// DeleteMedia deletes the torrents of the media using the given mode. RemovalModeRemoveWithData and RemovalModeRelabel
+// delete the media files as well. Relabeled torrents keep seeding and are listed as orphans after the next refresh.
+// Otherwise, the media is kept and evaluated again.
+func (s *Service) DeleteMedia(ctx context.Context, rawId string, mode domain.RemovalMode) error {
 	s.Lock()
 	defer s.Unlock()
 	id, err := parseMediaId(rawId)
 	if err != nil {
 		return err
 	}
+	if s.torrentSourceDegraded {
+		return fmt.Errorf("refusing to delete media %q while a torrent source is degraded", rawId)
+	}
 	// retrieve entry
 	entryIndex := slices.IndexFunc(s.enrichedLinkedMediaCache, func(media enrichedLinkedMedia) bool {
-		return media.linkedMedia.Type == id.MediaType && media.linkedMedia.Id == id.Id
+		return id.matchesMedia(media.linkedMedia.MediaMetadata)
 	})
 	if entryIndex == -1 {
 		return webserver.ErrMediaNotFound
@@ -395,29 +585,42 @@ func (s *Service) DeleteMedia(rawId string) error {
 		return webserver.ErrMediaNotFound
 	}
 
+	// reject the mode up front if any of the clients does not support it, so that it is not applied to some torrents only
+	for _, affectedFileIndex := range affectedFileIndexes {
+		for _, torrentEntry := range entry.linkedMedia.Files[affectedFileIndex].TorrentEntries {
+			if !s.torrentSourceManager.SupportsRemovalMode(torrentEntry.Client, mode) {
+				return fmt.Errorf("could not delete torrent %s for linked media %q: %w", torrentEntry, entry.linkedMedia.Title, domain.ErrRemovalModeNotSupported)
+			}
+		}
+	}
+
 	deletedTorrentEntries := make(map[*domain.TorrentEntry]struct{})
 	fileIdsToDelete := make([]int64, 0)
 
 	// delete torrent entries
 	for _, affectedFileIndex := range affectedFileIndexes {
 		affectedFile := entry.linkedMedia.Files[affectedFileIndex]
-		if affectedFile.TorrentEntry != nil {
-			_, ok := deletedTorrentEntries[affectedFile.TorrentEntry]
-			if !ok {
-				err = s.torrentSourceManager.DeleteTorrent(affectedFile.TorrentEntry.Client, affectedFile.TorrentEntry.Id)
-				if errors.Is(err, domain.ErrTorrentNotFound) {
-					slog.Warn("could not find torrent entry for deletion", "linkedMediaTitle", entry.linkedMedia.Title, "file", affectedFile)
-				} else if err != nil {
-					return fmt.Errorf("could not delete torrent for linked media %q (file: %+v): %w", entry.linkedMedia.Title, affectedFile, err)
-				}
-				deletedTorrentEntries[affectedFile.TorrentEntry] = struct{}{}
+		for _, torrentEntry := range affectedFile.TorrentEntries {
+			if _, ok := deletedTorrentEntries[torrentEntry]; ok {
+				continue
 			}
+			err = s.torrentSourceManager.DeleteTorrent(ctx, torrentEntry.Client, torrentEntry.Id, mode)
+			if errors.Is(err, domain.ErrTorrentNotFound) {
+				slog.Warn("could not find torrent entry for deletion", "linkedMediaTitle", entry.linkedMedia.Title, "file", affectedFile, "torrentEntry", torrentEntry)
+			} else if err != nil {
+				return fmt.Errorf("could not delete torrent %s for linked media %q (file: %+v): %w", torrentEntry, entry.linkedMedia.Title, affectedFile, err)
+			}
+			deletedTorrentEntries[torrentEntry] = struct{}{}
 		}
 		fileIdsToDelete = append(fileIdsToDelete, affectedFile.Id)
 	}
 
+	if mode == domain.RemovalModePause || mode == domain.RemovalModeRemoveKeepData {
+		return s.updateLinkedMediaTorrents(entryIndex, deletedTorrentEntries, mode)
+	}
+
 	// delete media files
-	err = s.mediaSourceManager.DeleteMediaFiles(entry.linkedMedia.Type, fileIdsToDelete, true)
+	err = s.mediaSourceManager.DeleteMediaFiles(ctx, entry.linkedMedia.Instance, fileIdsToDelete, true)
 	if err != nil {
 		return fmt.Errorf("could not delete media files: %w", err)
 	}
@@ -435,7 +638,33 @@ func (s *Service) DeleteMedia(rawId string) error {
 	return nil
 }
 
-func (s *Service) DeleteOrphanedTorrent(rawId string) error {
+// updateLinkedMediaTorrents evaluates the cached media again after its torrents were paused or removed without data.
+// Removed torrents are unlinked from all files of the media.
+func (s *Service) updateLinkedMediaTorrents(entryIndex int, torrentEntries map[*domain.TorrentEntry]struct{}, mode domain.RemovalMode) error {
+	entry := s.enrichedLinkedMediaCache[entryIndex]
+	if mode == domain.RemovalModeRemoveKeepData {
+		files := slices.Clone(entry.linkedMedia.Files)
+		for i, file := range files {
+			files[i].TorrentEntries = slices.DeleteFunc(slices.Clone(file.TorrentEntries), func(torrentEntry *domain.TorrentEntry) bool {
+				_, ok := torrentEntries[torrentEntry]
+				return ok
+			})
+		}
+		entry.linkedMedia.Files = files
+	}
+	evaluationReport, err := s.retentionPolicy.Evaluate(entry.linkedMedia)
+	if err != nil {
+		return fmt.Errorf("could not evaluate retention policy for linked media %q: %w", entry.linkedMedia.Title, err)
+	}
+	entry.evaluationReport = evaluationReport
+	entry.added = getAdded(entry.linkedMedia)
+	s.enrichedLinkedMediaCache[entryIndex] = entry
+	return nil
+}
+
+// DeleteOrphanedTorrent deletes the orphaned torrent using the given mode. Paused and relabeled torrents stay in the
+// list.
+func (s *Service) DeleteOrphanedTorrent(ctx context.Context, rawId string, mode domain.RemovalMode) error {
 	s.Lock()
 	defer s.Unlock()
 	parts := strings.SplitN(rawId, "-", 2)
@@ -443,6 +672,9 @@ func (s *Service) DeleteOrphanedTorrent(rawId string) error {
 		return fmt.Errorf("invalid orphaned torrent id: %q", rawId)
 	}
 	client, torrentId := parts[0], parts[1]
+	if s.mediaSourceDegraded {
+		return fmt.Errorf("refusing to delete orphaned torrent %q/%q while a media source is degraded", client, torrentId)
+	}
 
 	entryIndex := slices.IndexFunc(s.orphanedTorrentsCache, func(e enrichedOrphanedTorrent) bool {
 		return e.torrentEntry.Client == client && e.torrentEntry.Id == torrentId
@@ -451,11 +683,21 @@ func (s *Service) DeleteOrphanedTorrent(rawId string) error {
 		return webserver.ErrMediaNotFound
 	}
 
-	err := s.torrentSourceManager.DeleteTorrent(client, torrentId)
+	err := s.torrentSourceManager.DeleteTorrent(ctx, client, torrentId, mode)
 	if err != nil && !errors.Is(err, domain.ErrTorrentNotFound) {
 		return fmt.Errorf("could not delete orphaned torrent %q/%q: %w", client, torrentId, err)
 	}
 
+	if (mode == domain.RemovalModePause || mode == domain.RemovalModeRelabel) && err == nil {
+		entry := s.orphanedTorrentsCache[entryIndex]
+		entry.decision, entry.tracker, err = s.retentionPolicy.EvaluateTorrentEntry(entry.torrentEntry)
+		if err != nil {
+			return fmt.Errorf("could not evaluate orphaned torrent %q/%q: %w", client, torrentId, err)
+		}
+		s.orphanedTorrentsCache[entryIndex] = entry
+		return nil
+	}
+
 	s.orphanedTorrentsCache = append(s.orphanedTorrentsCache[:entryIndex], s.orphanedTorrentsCache[entryIndex+1:]...)
 	return nil
 }
@@ -1,13 +1,16 @@
 package inventory
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
 )
@@ -54,66 +57,84 @@ func (s *Service) saveManagerCacheToDisk(manager domain.CachedManager) error {
 	return manager.SaveCache(file)
 }
 
-func (s *Service) RefreshCache() error {
+// RefreshCache fetches all sources and rebuilds the cached inventory. The sources are bounded by their connection
+// timeouts and by ctx, so a cancelled refresh releases the inventory lock.
+func (s *Service) RefreshCache(ctx context.Context) error {
 	s.Lock()
 	defer s.Unlock()
-	errChan := make(chan error)
-	defer close(errChan)
-	refreshManagerCache := func(manager domain.CachedManager) {
-		var err error
-		defer func() {
-			errChan <- err
-		}()
+	refreshManagerCache := func(manager domain.CachedManager) (err error) {
 		if s.useCache {
-			err = s.loadManagerCacheFromDisk(manager)
-			return
+			return s.loadManagerCacheFromDisk(manager)
 		}
-		err = manager.RefreshCache()
+		err = manager.RefreshCache(ctx)
 		if err != nil {
-			return
+			return err
 		}
 		if s.saveCache {
-			err = s.saveManagerCacheToDisk(manager)
-			return
+			return s.saveManagerCacheToDisk(manager)
 		}
+		return nil
 	}
-	go refreshManagerCache(s.mediaSourceManager)
-	go refreshManagerCache(s.torrentSourceManager)
-	err := <-errChan
-	if refreshErr := <-errChan; refreshErr != nil {
-		if err != nil {
-			err = errors.Join(err, refreshErr)
-		} else {
-			err = refreshErr
-		}
+	var mediaErr, torrentErr error
+	wg := &sync.WaitGroup{}
+	wg.Go(func() {
+		mediaErr = refreshManagerCache(s.mediaSourceManager)
+	})
+	wg.Go(func() {
+		torrentErr = refreshManagerCache(s.torrentSourceManager)
+	})
+	wg.Wait()
+	err := errors.Join(mediaErr, torrentErr)
+	// degraded sources keep their last known entries, so the remaining data can still be used
+	if (mediaErr != nil && !errors.Is(mediaErr, domain.ErrSourceDegraded)) ||
+		(torrentErr != nil && !errors.Is(torrentErr, domain.ErrSourceDegraded)) {
+		return fmt.Errorf("refresh cache failed: %w", err)
 	}
 	if err != nil {
-		return fmt.Errorf("refresh cache failed: %w", err)
+		slog.Warn("Some sources are degraded. Using last known data for them.", "error", err)
 	}
+	// torrents of media on a degraded instance would wrongly show up as orphaned
+	s.mediaSourceDegraded = mediaErr != nil
+	s.torrentSourceDegraded = torrentErr != nil
 
-	media, err := s.mediaSourceManager.GetMedia()
+	media, err := s.mediaSourceManager.GetMedia(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get media: %w", err)
 	}
-	torrents, err := s.torrentSourceManager.GetTorrents()
+	torrents, err := s.torrentSourceManager.GetTorrents(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get torrents: %w", err)
 	}
+	if s.seedStore != nil {
+		s.applySeedObservations(torrents)
+	}
 	linkedMediaList, err := s.linker.LinkMedia(media, torrents)
 	if err != nil {
 		return fmt.Errorf("unable to link media with torrents: %w", err)
 	}
 
+	var calculator *reclaimableSizeCalculator
+	getReclaimableSize := func([]string) int64 { return -1 }
+	if s.filesystemInspector != nil {
+		calculator = newReclaimableSizeCalculator(s.filesystemInspector)
+		getReclaimableSize = calculator.getReclaimableSize
+	}
+
 	s.enrichedLinkedMediaCache = make([]enrichedLinkedMedia, len(linkedMediaList))
 	for i, linkedMedia := range linkedMediaList {
 		evaluationReport, err := s.retentionPolicy.Evaluate(linkedMedia)
 		if err != nil {
 			return fmt.Errorf("unable to evaluate retention policy: %w", err)
 		}
+		// the torrents of media on a degraded client are missing, so the media would wrongly be safe to delete
+		if s.torrentSourceDegraded {
+			evaluationReport = markUnlinkedFilesPending(linkedMedia, evaluationReport)
+		}
 		s.enrichedLinkedMediaCache[i] = enrichedLinkedMedia{
 			linkedMedia:      linkedMedia,
 			evaluationReport: evaluationReport,
 			size:             getSize(linkedMedia),
+			reclaimableSize:  getReclaimableSize(getLinkedMediaPaths(linkedMedia)),
 			added:            getAdded(linkedMedia),
 		}
 	}
@@ -125,8 +146,8 @@ func (s *Service) RefreshCache() error {
 	usedTorrentKeys := make(map[string]struct{})
 	for _, lm := range linkedMediaList {
 		for _, file := range lm.Files {
-			if file.TorrentEntry != nil {
-				usedTorrentKeys[uniqueTorrentId(file.TorrentEntry.Client, file.TorrentEntry.Id)] = struct{}{}
+			for _, torrentEntry := range file.TorrentEntries {
+				usedTorrentKeys[uniqueTorrentId(torrentEntry.Client, torrentEntry.Id)] = struct{}{}
 			}
 		}
 	}
@@ -142,12 +163,72 @@ func (s *Service) RefreshCache() error {
 				return fmt.Errorf("unable to evaluate orphaned torrent entry: %w", err)
 			}
 			s.orphanedTorrentsCache = append(s.orphanedTorrentsCache, enrichedOrphanedTorrent{
-				torrentEntry: t,
-				size:         size,
-				decision:     decision,
-				tracker:      tracker,
+				torrentEntry:    t,
+				size:            size,
+				reclaimableSize: getReclaimableSize(getTorrentEntryPaths(t)),
+				decision:        decision,
+				tracker:         tracker,
 			})
 		}
 	}
+
+	s.orphanedFilesCache = nil
+	if calculator != nil {
+		s.orphanedFilesCache, err = findOrphanedFiles(calculator, media, torrents)
+		if err != nil {
+			slog.Warn("Could not scan for orphaned files.", "error", err)
+		}
+	}
 	return nil
 }
+
+// markUnlinkedFilesPending returns a copy of the report in which all files without torrents as well as their seasons and
+// the media itself are pending.
+func markUnlinkedFilesPending(linkedMedia LinkedMedia, report EvaluationReport) EvaluationReport {
+	report.Seasons = maps.Clone(report.Seasons)
+	report.Files = maps.Clone(report.Files)
+	pendingPart := func(part EvaluationReportPart) EvaluationReportPart {
+		part.Decision = domain.DecisionPending
+		return part
+	}
+	for _, file := range linkedMedia.Files {
+		if len(file.TorrentEntries) > 0 {
+			continue
+		}
+		report.Result = pendingPart(report.Result)
+		if seasonReport, ok := report.Seasons[file.Season]; ok {
+			report.Seasons[file.Season] = pendingPart(seasonReport)
+		}
+		if fileReport, ok := report.Files[file.Id]; ok {
+			report.Files[file.Id] = pendingPart(fileReport)
+		}
+	}
+	return report
+}
+
+// applySeedObservations records the torrents in the seed store and uses the observed seeding time for torrents whose
+// client does not track it. The observed uploaded bytes take the place of the reported ratio if they are higher, so
+// that torrents which have been re-added keep the ratio they had seeded before.
+func (s *Service) applySeedObservations(torrents []*domain.TorrentEntry) {
+	observations, err := s.seedStore.Observe(torrents, now())
+	if err != nil {
+		slog.Warn("Could not update seed store. Using the seeding times and ratios reported by the clients.", "error", err)
+		return
+	}
+	for _, torrent := range torrents {
+		observation, ok := observations[[2]string{torrent.Client, torrent.Id}]
+		if !ok {
+			continue
+		}
+		if torrent.SeedingTime < 0 || torrent.SeedingTimeEstimated {
+			torrent.SeedingTime = observation.SeedingTime
+		}
+		size := int64(0)
+		for _, file := range torrent.Files {
+			size += file.Size
+		}
+		if size > 0 {
+			torrent.Ratio = max(torrent.Ratio, float64(observation.Uploaded)/float64(size))
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package inventory
 
 import (
+	"context"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,19 +14,22 @@ import (
 )
 
 var testTorrentInformationMissing = webserver.TorrentInformation{
-	LinkStatus: webserver.TorrentLinkMissing,
-	Tracker:    domain.Tracker{},
-	Ratio:      -1.0,
-	Age:        time.Duration(-1),
+	LinkStatus:  webserver.TorrentLinkMissing,
+	Tracker:     domain.Tracker{},
+	Ratio:       -1.0,
+	Age:         time.Duration(-1),
+	SeedingTime: time.Duration(-1),
 }
 
 func Test_generateRawFileBasedMediaRow(t *testing.T) {
 	torrentEntry1 := &domain.TorrentEntry{
 		Ratio: 2.6,
+		State: domain.TorrentStateSeeding,
 		Added: util.MustParseDate("2022-08-12 00:00:00"),
 	}
 	torrentEntry2 := &domain.TorrentEntry{
 		Ratio: 0.9,
+		State: domain.TorrentStatePaused,
 		Added: util.MustParseDate("2023-08-11 00:00:00"),
 	}
 	now = func() time.Time {
@@ -43,11 +49,12 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 				media: enrichedLinkedMedia{
 					linkedMedia: LinkedMedia{
 						MediaMetadata: domain.MediaMetadata{
-							Id:    1337,
-							Type:  domain.MediaTypeMovie,
-							Title: "Some movie title",
-							Url:   "http://example.com/movie.mp4",
-							Added: util.MustParseDate("2021-08-12 00:00:00"),
+							Id:       1337,
+							Instance: "radarr",
+							Type:     domain.MediaTypeMovie,
+							Title:    "Some movie title",
+							Url:      "http://example.com/movie.mp4",
+							Added:    util.MustParseDate("2021-08-12 00:00:00"),
 						},
 						Files: []LinkedMediaFile{
 							{
@@ -56,10 +63,10 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									OriginalFilePath: "some/path/anywhere/Some-movie-title-1080p.mp4",
 									Size:             int64(8232),
 								},
-								TorrentEntry: &domain.TorrentEntry{
+								TorrentEntries: []*domain.TorrentEntry{{
 									Ratio: 1.4,
 									Added: util.MustParseDate("2022-08-12 00:00:00"),
-								},
+								}},
 							},
 						},
 					},
@@ -68,26 +75,86 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 				},
 			},
 			want: webserver.MediaRow{
-				Id:    "movie-1337",
+				Id:    "movie-radarr-1337",
 				Type:  domain.MediaTypeMovie,
 				Title: "Some movie title",
 				Url:   "http://example.com/movie.mp4",
 				Size:  3827948,
 				Added: util.MustParseDate("2021-08-12 00:00:00"),
 				TorrentInformation: webserver.TorrentInformation{
-					LinkStatus: webserver.TorrentLinkPresent,
-					Ratio:      1.4,
-					Age:        time.Hour * 24 * 365,
+					LinkStatus:   webserver.TorrentLinkPresent,
+					Ratio:        1.4,
+					Age:          time.Hour * 24 * 365,
+					TorrentCount: 1,
 				},
 				ChildMediaRows: []webserver.MediaRow{{
-					Id:    "movie-1337-10",
+					Id:    "movie-radarr-1337-10",
 					Title: "Some-movie-title-1080p.mp4",
 					Size:  int64(8232),
 					Added: util.MustParseDate("2022-08-12 00:00:00"),
 					TorrentInformation: webserver.TorrentInformation{
-						LinkStatus: webserver.TorrentLinkPresent,
-						Ratio:      1.4,
-						Age:        time.Hour * 24 * 365,
+						LinkStatus:   webserver.TorrentLinkPresent,
+						Ratio:        1.4,
+						Age:          time.Hour * 24 * 365,
+						TorrentCount: 1,
+					},
+					ChildMediaRows: []webserver.MediaRow{},
+				}},
+			},
+		},
+		{
+			name: "media entry with cross-seeded file",
+			args: args{
+				media: enrichedLinkedMedia{
+					linkedMedia: LinkedMedia{
+						MediaMetadata: domain.MediaMetadata{
+							Id:       1337,
+							Instance: "radarr",
+							Type:     domain.MediaTypeMovie,
+							Title:    "Some movie title",
+							Url:      "http://example.com/movie.mp4",
+							Added:    util.MustParseDate("2021-08-12 00:00:00"),
+						},
+						Files: []LinkedMediaFile{
+							{
+								MediaFile: domain.MediaFile{
+									Id:               10,
+									OriginalFilePath: "some/path/anywhere/Some-movie-title-1080p.mp4",
+									Size:             int64(8232),
+								},
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1, torrentEntry2},
+							},
+						},
+					},
+					size:  8232,
+					added: util.MustParseDate("2021-08-12 00:00:00"),
+				},
+			},
+			want: webserver.MediaRow{
+				Id:    "movie-radarr-1337",
+				Type:  domain.MediaTypeMovie,
+				Title: "Some movie title",
+				Url:   "http://example.com/movie.mp4",
+				Size:  8232,
+				Added: util.MustParseDate("2021-08-12 00:00:00"),
+				TorrentInformation: webserver.TorrentInformation{
+					LinkStatus:   webserver.TorrentLinkPresent,
+					Ratio:        torrentEntry2.Ratio,
+					State:        torrentEntry2.State,
+					Age:          time.Hour * 24,
+					TorrentCount: 2,
+				},
+				ChildMediaRows: []webserver.MediaRow{{
+					Id:    "movie-radarr-1337-10",
+					Title: "Some-movie-title-1080p.mp4",
+					Size:  int64(8232),
+					Added: torrentEntry2.Added,
+					TorrentInformation: webserver.TorrentInformation{
+						LinkStatus:   webserver.TorrentLinkPresent,
+						Ratio:        torrentEntry2.Ratio,
+						State:        torrentEntry2.State,
+						Age:          time.Hour * 24,
+						TorrentCount: 2,
 					},
 					ChildMediaRows: []webserver.MediaRow{},
 				}},
@@ -101,10 +168,11 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 					added: util.MustParseDate("2020-08-12 00:00:00"),
 					linkedMedia: LinkedMedia{
 						MediaMetadata: domain.MediaMetadata{
-							Id:    1337,
-							Type:  domain.MediaTypeSeries,
-							Title: "Breaking Bad",
-							Url:   "https://some-series.com/series/breaking-bad",
+							Id:       1337,
+							Instance: "sonarr",
+							Type:     domain.MediaTypeSeries,
+							Title:    "Breaking Bad",
+							Url:      "https://some-series.com/series/breaking-bad",
 						},
 						Files: []LinkedMediaFile{
 							{
@@ -114,7 +182,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           1,
 									Size:             1000,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
@@ -123,7 +191,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           1,
 									Size:             1000,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
@@ -132,58 +200,65 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           2,
 									Size:             1500,
 								},
-								TorrentEntry: torrentEntry2,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry2},
 							},
 						},
 					},
 				},
 			},
 			want: webserver.MediaRow{
-				Id:    "series-1337",
+				Id:    "series-sonarr-1337",
 				Type:  domain.MediaTypeSeries,
 				Title: "Breaking Bad",
 				Url:   "https://some-series.com/series/breaking-bad",
 				Size:  9000,
 				Added: util.MustParseDate("2020-08-12 00:00:00"),
 				TorrentInformation: webserver.TorrentInformation{
-					LinkStatus: webserver.TorrentLinkPresent,
-					Ratio:      -1.0,
-					Age:        time.Duration(-1),
+					LinkStatus:  webserver.TorrentLinkPresent,
+					Ratio:       -1.0,
+					Age:         time.Duration(-1),
+					SeedingTime: time.Duration(-1),
 				},
 				ChildMediaRows: []webserver.MediaRow{
 					{
-						Id:    "series-1337-13371",
+						Id:    "series-sonarr-1337-13371",
 						Title: "Breaking Bad S1 E1.mp4",
 						Size:  1000,
 						Added: util.MustParseDate("2022-08-12 00:00:00"),
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      torrentEntry1.Ratio,
-							Age:        now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							LinkStatus:   webserver.TorrentLinkPresent,
+							Ratio:        torrentEntry1.Ratio,
+							State:        torrentEntry1.State,
+							Age:          now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							TorrentCount: 1,
 						},
 						ChildMediaRows: []webserver.MediaRow{},
 					},
 					{
-						Id:    "series-1337-13372",
+						Id:    "series-sonarr-1337-13372",
 						Title: "Breaking Bad S1 E2.mp4",
 						Size:  1000,
 						Added: util.MustParseDate("2022-08-12 00:00:00"),
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      torrentEntry1.Ratio,
-							Age:        now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							LinkStatus:   webserver.TorrentLinkPresent,
+							Ratio:        torrentEntry1.Ratio,
+							State:        torrentEntry1.State,
+							Age:          now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							TorrentCount: 1,
 						},
 						ChildMediaRows: []webserver.MediaRow{},
 					},
 					{
-						Id:    "series-1337-13373",
+						Id:    "series-sonarr-1337-13373",
 						Title: "Breaking Bad S2 E1.mp4",
 						Size:  1500,
 						Added: util.MustParseDate("2023-08-11 00:00:00"),
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      torrentEntry2.Ratio,
-							Age:        now().Sub(util.MustParseDate("2023-08-11 00:00:00")),
+							LinkStatus:   webserver.TorrentLinkPresent,
+							Ratio:        torrentEntry2.Ratio,
+							State:        torrentEntry2.State,
+							Age:          now().Sub(util.MustParseDate("2023-08-11 00:00:00")),
+							TorrentCount: 1,
 						},
 						ChildMediaRows: []webserver.MediaRow{},
 					},
@@ -196,11 +271,12 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 				media: enrichedLinkedMedia{
 					linkedMedia: LinkedMedia{
 						MediaMetadata: domain.MediaMetadata{
-							Id:    1337,
-							Type:  domain.MediaTypeMovie,
-							Title: "Some movie title",
-							Url:   "http://example.com/movie.mp4",
-							Added: util.MustParseDate("2021-08-12 00:00:00"),
+							Id:       1337,
+							Instance: "radarr",
+							Type:     domain.MediaTypeMovie,
+							Title:    "Some movie title",
+							Url:      "http://example.com/movie.mp4",
+							Added:    util.MustParseDate("2021-08-12 00:00:00"),
 						},
 						Files: []LinkedMediaFile{
 							{
@@ -209,7 +285,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									OriginalFilePath: "some/path/anywhere/Some-movie-title-1080p.mp4",
 									Size:             int64(8232),
 								},
-								TorrentEntry: nil,
+								TorrentEntries: nil,
 							},
 						},
 					},
@@ -218,7 +294,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 				},
 			},
 			want: webserver.MediaRow{
-				Id:                 "movie-1337",
+				Id:                 "movie-radarr-1337",
 				Type:               domain.MediaTypeMovie,
 				Title:              "Some movie title",
 				Url:                "http://example.com/movie.mp4",
@@ -226,7 +302,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 				Added:              util.MustParseDate("2021-08-12 00:00:00"),
 				TorrentInformation: testTorrentInformationMissing,
 				ChildMediaRows: []webserver.MediaRow{{
-					Id:                 "movie-1337-10",
+					Id:                 "movie-radarr-1337-10",
 					Title:              "Some-movie-title-1080p.mp4",
 					Size:               int64(8232),
 					TorrentInformation: testTorrentInformationMissing,
@@ -242,10 +318,11 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 					added: util.MustParseDate("2020-08-12 00:00:00"),
 					linkedMedia: LinkedMedia{
 						MediaMetadata: domain.MediaMetadata{
-							Id:    1337,
-							Type:  domain.MediaTypeSeries,
-							Title: "Breaking Bad",
-							Url:   "https://some-series.com/series/breaking-bad",
+							Id:       1337,
+							Instance: "sonarr",
+							Type:     domain.MediaTypeSeries,
+							Title:    "Breaking Bad",
+							Url:      "https://some-series.com/series/breaking-bad",
 						},
 						Files: []LinkedMediaFile{
 							{
@@ -255,7 +332,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           1,
 									Size:             1000,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
@@ -264,7 +341,7 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           1,
 									Size:             1000,
 								},
-								TorrentEntry: nil,
+								TorrentEntries: nil,
 							},
 							{
 								MediaFile: domain.MediaFile{
@@ -273,39 +350,42 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 									Season:           2,
 									Size:             1500,
 								},
-								TorrentEntry: torrentEntry2,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry2},
 							},
 						},
 					},
 				},
 			},
 			want: webserver.MediaRow{
-				Id:    "series-1337",
+				Id:    "series-sonarr-1337",
 				Type:  domain.MediaTypeSeries,
 				Title: "Breaking Bad",
 				Url:   "https://some-series.com/series/breaking-bad",
 				Size:  9000,
 				Added: util.MustParseDate("2020-08-12 00:00:00"),
 				TorrentInformation: webserver.TorrentInformation{
-					LinkStatus: webserver.TorrentLinkIncomplete,
-					Ratio:      -1.0,
-					Age:        time.Duration(-1),
+					LinkStatus:  webserver.TorrentLinkIncomplete,
+					Ratio:       -1.0,
+					Age:         time.Duration(-1),
+					SeedingTime: time.Duration(-1),
 				},
 				ChildMediaRows: []webserver.MediaRow{
 					{
-						Id:    "series-1337-13371",
+						Id:    "series-sonarr-1337-13371",
 						Title: "Breaking Bad S1 E1.mp4",
 						Size:  1000,
 						Added: util.MustParseDate("2022-08-12 00:00:00"),
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      torrentEntry1.Ratio,
-							Age:        now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							LinkStatus:   webserver.TorrentLinkPresent,
+							Ratio:        torrentEntry1.Ratio,
+							State:        torrentEntry1.State,
+							Age:          now().Sub(util.MustParseDate("2022-08-12 00:00:00")),
+							TorrentCount: 1,
 						},
 						ChildMediaRows: []webserver.MediaRow{},
 					},
 					{
-						Id:                 "series-1337-13372",
+						Id:                 "series-sonarr-1337-13372",
 						Title:              "Breaking Bad S1 E2.mp4",
 						Size:               1000,
 						Added:              time.Time{},
@@ -313,14 +393,16 @@ func Test_generateRawFileBasedMediaRow(t *testing.T) {
 						ChildMediaRows:     []webserver.MediaRow{},
 					},
 					{
-						Id:    "series-1337-13373",
+						Id:    "series-sonarr-1337-13373",
 						Title: "Breaking Bad S2 E1.mp4",
 						Size:  1500,
 						Added: util.MustParseDate("2023-08-11 00:00:00"),
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      torrentEntry2.Ratio,
-							Age:        now().Sub(util.MustParseDate("2023-08-11 00:00:00")),
+							LinkStatus:   webserver.TorrentLinkPresent,
+							Ratio:        torrentEntry2.Ratio,
+							State:        torrentEntry2.State,
+							Age:          now().Sub(util.MustParseDate("2023-08-11 00:00:00")),
+							TorrentCount: 1,
 						},
 						ChildMediaRows: []webserver.MediaRow{},
 					},
@@ -665,14 +747,14 @@ func Test_applyEvaluationReport(t *testing.T) {
 									Id:     1337_1,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
 									Id:     1337_2,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 						},
 					},
@@ -755,14 +837,14 @@ func Test_applyEvaluationReport(t *testing.T) {
 									Id:     1337_1,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
 									Id:     1337_2,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry2,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry2},
 							},
 						},
 					},
@@ -786,9 +868,10 @@ func Test_applyEvaluationReport(t *testing.T) {
 				row: webserver.MediaRow{
 					Id: "series-10",
 					TorrentInformation: webserver.TorrentInformation{
-						LinkStatus: webserver.TorrentLinkPresent,
-						Ratio:      -1.0,
-						Age:        time.Duration(-1),
+						LinkStatus:  webserver.TorrentLinkPresent,
+						Ratio:       -1.0,
+						Age:         time.Duration(-1),
+						SeedingTime: time.Duration(-1),
 					},
 					ChildMediaRows: []webserver.MediaRow{
 						{
@@ -808,10 +891,11 @@ func Test_applyEvaluationReport(t *testing.T) {
 				Id:       "series-10",
 				Decision: domain.DecisionPending,
 				TorrentInformation: webserver.TorrentInformation{
-					LinkStatus: webserver.TorrentLinkPresent,
-					Tracker:    *tracker,
-					Ratio:      -1.0,
-					Age:        time.Duration(-1),
+					LinkStatus:  webserver.TorrentLinkPresent,
+					Tracker:     *tracker,
+					Ratio:       -1.0,
+					Age:         time.Duration(-1),
+					SeedingTime: time.Duration(-1),
 				},
 				AllowDeletion: true,
 				ChildMediaRows: []webserver.MediaRow{
@@ -819,10 +903,11 @@ func Test_applyEvaluationReport(t *testing.T) {
 						Id:    "series-10-s-1",
 						Title: "Season 1",
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Tracker:    *tracker,
-							Ratio:      -1.0,
-							Age:        time.Duration(-1),
+							LinkStatus:  webserver.TorrentLinkPresent,
+							Tracker:     *tracker,
+							Ratio:       -1.0,
+							Age:         time.Duration(-1),
+							SeedingTime: time.Duration(-1),
 						},
 						Decision:      domain.DecisionPending,
 						AllowDeletion: true,
@@ -860,14 +945,14 @@ func Test_applyEvaluationReport(t *testing.T) {
 									Id:     1337_1,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry1,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry1},
 							},
 							{
 								MediaFile: domain.MediaFile{
 									Id:     1337_2,
 									Season: 1,
 								},
-								TorrentEntry: torrentEntry2,
+								TorrentEntries: []*domain.TorrentEntry{torrentEntry2},
 							},
 						},
 					},
@@ -891,9 +976,10 @@ func Test_applyEvaluationReport(t *testing.T) {
 				row: webserver.MediaRow{
 					Id: "series-10",
 					TorrentInformation: webserver.TorrentInformation{
-						LinkStatus: webserver.TorrentLinkPresent,
-						Ratio:      -1.0,
-						Age:        time.Duration(-1),
+						LinkStatus:  webserver.TorrentLinkPresent,
+						Ratio:       -1.0,
+						Age:         time.Duration(-1),
+						SeedingTime: time.Duration(-1),
 					},
 					ChildMediaRows: []webserver.MediaRow{
 						{
@@ -913,9 +999,10 @@ func Test_applyEvaluationReport(t *testing.T) {
 				Id:       "series-10",
 				Decision: domain.DecisionPending,
 				TorrentInformation: webserver.TorrentInformation{
-					LinkStatus: webserver.TorrentLinkPresent,
-					Ratio:      -1.0,
-					Age:        time.Duration(-1),
+					LinkStatus:  webserver.TorrentLinkPresent,
+					Ratio:       -1.0,
+					Age:         time.Duration(-1),
+					SeedingTime: time.Duration(-1),
 				},
 				AllowDeletion: true,
 				ChildMediaRows: []webserver.MediaRow{
@@ -923,9 +1010,10 @@ func Test_applyEvaluationReport(t *testing.T) {
 						Id:    "series-10-s-1",
 						Title: "Season 1",
 						TorrentInformation: webserver.TorrentInformation{
-							LinkStatus: webserver.TorrentLinkPresent,
-							Ratio:      -1.0,
-							Age:        time.Duration(-1),
+							LinkStatus:  webserver.TorrentLinkPresent,
+							Ratio:       -1.0,
+							Age:         time.Duration(-1),
+							SeedingTime: time.Duration(-1),
 						},
 						Decision:      domain.DecisionPending,
 						AllowDeletion: true,
@@ -962,3 +1050,243 @@ func Test_applyEvaluationReport(t *testing.T) {
 		})
 	}
 }
+
+func Test_getMediaFileDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		file domain.MediaFile
+		want string
+	}{
+		{
+			name: "file without details",
+			file: domain.MediaFile{Id: 1, OriginalFilePath: "movie.mkv"},
+			want: "",
+		},
+		{
+			name: "movie file",
+			file: domain.MediaFile{Quality: "Bluray-2160p", Resolution: 2160, ReleaseGroup: "GROUP"},
+			want: "Bluray-2160p · GROUP",
+		},
+		{
+			name: "resolution without quality name",
+			file: domain.MediaFile{Resolution: 720},
+			want: "720p",
+		},
+		{
+			name: "single episode",
+			file: domain.MediaFile{
+				Season:         3,
+				EpisodeNumbers: []int{4},
+				EpisodeTitle:   "Some Episode",
+				AirDate:        util.MustParseDate("2024-01-02 00:00:00"),
+				Quality:        "WEBDL-1080p",
+				ReleaseGroup:   "GROUP",
+			},
+			want: "S03E04 – Some Episode · WEBDL-1080p · GROUP · aired 2024-01-02",
+		},
+		{
+			name: "multi episode",
+			file: domain.MediaFile{Season: 1, EpisodeNumbers: []int{1, 2}},
+			want: "S01E01-E02",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, getMediaFileDetails(tt.file))
+		})
+	}
+}
+
+func Test_matchesMediaFilter(t *testing.T) {
+	media := domain.MediaMetadata{
+		Id:             10,
+		Type:           domain.MediaTypeSeries,
+		Tags:           []string{"kids", "keep"},
+		QualityProfile: "HD-1080p",
+		RootFolder:     "/media/series",
+		Monitored:      true,
+		Ended:          false,
+	}
+	tests := []struct {
+		name   string
+		filter webserver.MediaFilter
+		want   bool
+	}{
+		{"empty filter", webserver.MediaFilter{}, true},
+		{"matching tag", webserver.MediaFilter{Tag: "keep"}, true},
+		{"other tag", webserver.MediaFilter{Tag: "4k"}, false},
+		{"matching quality profile and root folder", webserver.MediaFilter{QualityProfile: "HD-1080p", RootFolder: "/media/series"}, true},
+		{"other root folder", webserver.MediaFilter{RootFolder: "/media/anime"}, false},
+		{"monitored", webserver.MediaFilter{Monitored: "true"}, true},
+		{"unmonitored", webserver.MediaFilter{Monitored: "false"}, false},
+		{"not ended", webserver.MediaFilter{Ended: "false"}, true},
+		{"ended", webserver.MediaFilter{Ended: "true"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, matchesMediaFilter(media, tt.filter))
+		})
+	}
+}
+
+type staticSeedStore map[[2]string]SeedObservation
+
+func (store staticSeedStore) Observe([]*domain.TorrentEntry, time.Time) (map[[2]string]SeedObservation, error) {
+	return store, nil
+}
+
+func TestService_applySeedObservations(t *testing.T) {
+	files := []*domain.TorrentFile{{Path: "file.mkv", Size: 1000}}
+	tracked := &domain.TorrentEntry{Client: "deluge", Id: "tracked", Ratio: 0.5, SeedingTime: time.Hour, Files: files}
+	untracked := &domain.TorrentEntry{Client: "rtorrent", Id: "untracked", Ratio: 2.0, SeedingTime: -1, Files: files}
+	estimated := &domain.TorrentEntry{Client: "transmission", Id: "estimated", Ratio: 1.0, SeedingTime: 3 * time.Hour, SeedingTimeEstimated: true}
+	unobserved := &domain.TorrentEntry{Client: "deluge", Id: "unobserved", Ratio: 0.1, SeedingTime: -1, Files: files}
+	s := &Service{seedStore: staticSeedStore{
+		{"deluge", "tracked"}:         {SeedingTime: 5 * time.Hour, Uploaded: 1500},
+		{"rtorrent", "untracked"}:     {SeedingTime: 2 * time.Hour, Uploaded: 1000},
+		{"transmission", "estimated"}: {SeedingTime: 2 * time.Hour, Uploaded: 500},
+	}}
+
+	s.applySeedObservations([]*domain.TorrentEntry{tracked, untracked, estimated, unobserved})
+
+	// the client seeding time is kept, but the uploads observed before the torrent was re-added count towards the ratio
+	require.Equal(t, time.Hour, tracked.SeedingTime)
+	require.Equal(t, 1.5, tracked.Ratio)
+	// a higher reported ratio is kept
+	require.Equal(t, 2*time.Hour, untracked.SeedingTime)
+	require.Equal(t, 2.0, untracked.Ratio)
+	// the ratio of torrents without known size is kept
+	require.Equal(t, 2*time.Hour, estimated.SeedingTime)
+	require.Equal(t, 1.0, estimated.Ratio)
+	require.Equal(t, time.Duration(-1), unobserved.SeedingTime)
+	require.Equal(t, 0.1, unobserved.Ratio)
+}
+
+// fakeTorrentSourceManager deletes torrents of clients supporting the given removal modes like the torrent clients do.
+type fakeTorrentSourceManager struct {
+	domain.TorrentSourceManager
+	supportedModes  map[string][]domain.RemovalMode
+	deletedTorrents []string
+}
+
+func (manager *fakeTorrentSourceManager) SupportsRemovalMode(client string, mode domain.RemovalMode) bool {
+	return slices.Contains(manager.supportedModes[client], mode)
+}
+
+func (manager *fakeTorrentSourceManager) DeleteTorrent(_ context.Context, client string, id string, mode domain.RemovalMode) error {
+	if !manager.SupportsRemovalMode(client, mode) {
+		return domain.ErrRemovalModeNotSupported
+	}
+	manager.deletedTorrents = append(manager.deletedTorrents, client+"/"+id)
+	return nil
+}
+
+type staticRetentionPolicy struct {
+	RetentionPolicy
+	report EvaluationReport
+}
+
+func (policy staticRetentionPolicy) Evaluate(LinkedMedia) (EvaluationReport, error) {
+	return policy.report, nil
+}
+
+func TestService_DeleteMedia_unsupportedRemovalMode(t *testing.T) {
+	delugeTorrent := &domain.TorrentEntry{Client: "deluge", Id: "deluge-torrent"}
+	qbittorrentTorrent := &domain.TorrentEntry{Client: "qbittorrent", Id: "qbittorrent-torrent"}
+	crossSeededMovie := LinkedMedia{
+		MediaMetadata: domain.MediaMetadata{Instance: "radarr", Id: 1, Type: domain.MediaTypeMovie, Title: "Cross-Seeded Movie"},
+		Files: []LinkedMediaFile{{
+			MediaFile:      domain.MediaFile{Id: 10},
+			TorrentEntries: []*domain.TorrentEntry{delugeTorrent, qbittorrentTorrent},
+		}},
+	}
+	delugeMovie := LinkedMedia{
+		MediaMetadata: domain.MediaMetadata{Instance: "radarr", Id: 2, Type: domain.MediaTypeMovie, Title: "Deluge Movie"},
+		Files: []LinkedMediaFile{{
+			MediaFile:      domain.MediaFile{Id: 20},
+			TorrentEntries: []*domain.TorrentEntry{delugeTorrent},
+		}},
+	}
+	torrentSourceManager := &fakeTorrentSourceManager{supportedModes: map[string][]domain.RemovalMode{
+		"deluge":      {domain.RemovalModeRemoveWithData, domain.RemovalModeRemoveKeepData, domain.RemovalModePause, domain.RemovalModeRelabel},
+		"qbittorrent": {domain.RemovalModeRemoveWithData},
+	}}
+	pendingReport := EvaluationReport{Result: EvaluationReportPart{Decision: domain.DecisionPending}}
+	s := &Service{
+		RWMutex:              &sync.RWMutex{},
+		torrentSourceManager: torrentSourceManager,
+		retentionPolicy:      staticRetentionPolicy{report: pendingReport},
+		enrichedLinkedMediaCache: []enrichedLinkedMedia{
+			{linkedMedia: crossSeededMovie},
+			{linkedMedia: delugeMovie},
+		},
+	}
+
+	// only the modes supported by all clients are offered
+	row, err := s.GetExpandedMediaRow("movie-radarr-1")
+	require.NoError(t, err)
+	require.Equal(t, []domain.RemovalMode{domain.RemovalModeRemoveWithData}, row.RemovalModes)
+	row, err = s.GetExpandedMediaRow("movie-radarr-2")
+	require.NoError(t, err)
+	require.Equal(t, domain.RemovalModes, row.RemovalModes)
+
+	// the torrent on deluge is not paused as qbittorrent does not support pausing
+	err = s.DeleteMedia(context.Background(), "movie-radarr-1", domain.RemovalModePause)
+	require.ErrorIs(t, err, domain.ErrRemovalModeNotSupported)
+	require.Empty(t, torrentSourceManager.deletedTorrents)
+	require.Equal(t, crossSeededMovie, s.enrichedLinkedMediaCache[0].linkedMedia)
+
+	require.NoError(t, s.DeleteMedia(context.Background(), "movie-radarr-2", domain.RemovalModePause))
+	require.Equal(t, []string{"deluge/deluge-torrent"}, torrentSourceManager.deletedTorrents)
+	require.Equal(t, pendingReport, s.enrichedLinkedMediaCache[1].evaluationReport)
+}
+
+func Test_markUnlinkedFilesPending(t *testing.T) {
+	torrentEntry := &domain.TorrentEntry{Client: "deluge", Id: "torrent"}
+	linkedMedia := LinkedMedia{
+		MediaMetadata: domain.MediaMetadata{Instance: "sonarr", Id: 1, Type: domain.MediaTypeSeries, Title: "Series"},
+		Files: []LinkedMediaFile{
+			{MediaFile: domain.MediaFile{Id: 10, Season: 1}, TorrentEntries: []*domain.TorrentEntry{torrentEntry}},
+			{MediaFile: domain.MediaFile{Id: 20, Season: 2}},
+		},
+	}
+	safeToDelete := EvaluationReportPart{Decision: domain.DecisionSafeToDelete}
+	report := EvaluationReport{
+		Result:  safeToDelete,
+		Seasons: map[int]EvaluationReportPart{1: safeToDelete, 2: safeToDelete},
+		Files:   map[int64]EvaluationReportPart{10: safeToDelete, 20: safeToDelete},
+	}
+
+	pending := EvaluationReportPart{Decision: domain.DecisionPending}
+	require.Equal(t, EvaluationReport{
+		Result:  pending,
+		Seasons: map[int]EvaluationReportPart{1: safeToDelete, 2: pending},
+		Files:   map[int64]EvaluationReportPart{10: safeToDelete, 20: pending},
+	}, markUnlinkedFilesPending(linkedMedia, report))
+	// the report of the retention policy is not modified
+	require.Equal(t, safeToDelete, report.Files[20])
+}
+
+func TestService_torrentSourceDegraded(t *testing.T) {
+	movie := LinkedMedia{
+		MediaMetadata: domain.MediaMetadata{Instance: "radarr", Id: 1, Type: domain.MediaTypeMovie, Title: "Movie"},
+		Files:         []LinkedMediaFile{{MediaFile: domain.MediaFile{Id: 10, OriginalFilePath: "/movies/Movie.mkv"}}},
+	}
+	torrentSourceManager := &fakeTorrentSourceManager{}
+	s := &Service{
+		RWMutex:                  &sync.RWMutex{},
+		torrentSourceManager:     torrentSourceManager,
+		torrentSourceDegraded:    true,
+		enrichedLinkedMediaCache: []enrichedLinkedMedia{{linkedMedia: movie}},
+	}
+
+	row, err := s.GetExpandedMediaRow("movie-radarr-1")
+	require.NoError(t, err)
+	require.False(t, row.AllowDeletion)
+	require.False(t, row.ChildMediaRows[0].AllowDeletion)
+
+	err = s.DeleteMedia(context.Background(), "movie-radarr-1", domain.RemovalModeRemoveWithData)
+	require.ErrorContains(t, err, "refusing to delete media \"movie-radarr-1\" while a torrent source is degraded")
+	require.Empty(t, torrentSourceManager.deletedTorrents)
+	require.Len(t, s.enrichedLinkedMediaCache, 1)
+}
@@ -2,6 +2,7 @@ package linker
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
 	"github.com/almanac1631/scrubarr/pkg/inventory"
@@ -22,9 +23,9 @@ func (s Service) LinkMedia(mediaEntries []*domain.MediaEntry, torrentEntries []*
 			MediaMetadata: mediaEntry.MediaMetadata,
 		}
 		for _, mediaFile := range mediaEntry.Files {
-			linkedMediaFile, found := searchLinkedTorrentEntry(mediaFile, torrentEntries)
-			if !found {
-				linkedMediaFile = inventory.LinkedMediaFile{MediaFile: mediaFile}
+			linkedMediaFile := inventory.LinkedMediaFile{
+				MediaFile:      mediaFile,
+				TorrentEntries: searchLinkedTorrentEntries(mediaFile, torrentEntries),
 			}
 			if linkedMedia.Files == nil {
 				linkedMedia.Files = []inventory.LinkedMediaFile{linkedMediaFile}
@@ -39,34 +40,52 @@ func (s Service) LinkMedia(mediaEntries []*domain.MediaEntry, torrentEntries []*
 	return linkedMedias, nil
 }
 
-func searchLinkedTorrentEntry(mediaFile domain.MediaFile, torrentEntries []*domain.TorrentEntry) (inventory.LinkedMediaFile, bool) {
+// searchLinkedTorrentEntries returns all torrents seeding the media file. The torrent the file was imported from
+// according to the *arr history comes first, followed by torrents matching by name like cross-seeded ones.
+func searchLinkedTorrentEntries(mediaFile domain.MediaFile, torrentEntries []*domain.TorrentEntry) []*domain.TorrentEntry {
+	linkedTorrentEntries := make([]*domain.TorrentEntry, 0)
 	for _, torrentEntry := range torrentEntries {
-		linkedMediaFile := inventory.LinkedMediaFile{
-			MediaFile:    mediaFile,
-			TorrentEntry: torrentEntry,
+		if isLinkedByDownloadId(mediaFile, torrentEntry) {
+			linkedTorrentEntries = append(linkedTorrentEntries, torrentEntry)
 		}
-
-		if torrentEntry.Name == mediaFile.OriginalFilePath {
-			return linkedMediaFile, true
+	}
+	for _, torrentEntry := range torrentEntries {
+		if !isLinkedByDownloadId(mediaFile, torrentEntry) && isLinkedByName(mediaFile, torrentEntry) {
+			linkedTorrentEntries = append(linkedTorrentEntries, torrentEntry)
 		}
+	}
+	return linkedTorrentEntries
+}
 
-		torrentEntryNameWithExt := torrentEntry.Name + filepath.Ext(mediaFile.OriginalFilePath)
-		if torrentEntryNameWithExt == mediaFile.OriginalFilePath {
-			return linkedMediaFile, true
-		}
+// isLinkedByDownloadId reports whether the media file was imported from the torrent according to the *arr history. The
+// *arr instances report info hashes in upper case while most clients use lower case.
+func isLinkedByDownloadId(mediaFile domain.MediaFile, torrentEntry *domain.TorrentEntry) bool {
+	return mediaFile.DownloadId != "" && strings.EqualFold(torrentEntry.Id, mediaFile.DownloadId)
+}
 
-		for _, torrentEntryFile := range torrentEntry.Files {
-			if torrentEntryFile.Size != mediaFile.Size {
-				continue
-			}
-			if torrentEntryFile.Path == mediaFile.OriginalFilePath {
-				return linkedMediaFile, true
-			}
-			torrentFileBase := filepath.Base(torrentEntryFile.Path)
-			if torrentFileBase == mediaFile.OriginalFilePath {
-				return linkedMediaFile, true
-			}
+// isLinkedByName compares the torrent name and files with the original file name of the media file. This covers media
+// files without a download id as well as cross-seeded torrents.
+func isLinkedByName(mediaFile domain.MediaFile, torrentEntry *domain.TorrentEntry) bool {
+	if torrentEntry.Name == mediaFile.OriginalFilePath {
+		return true
+	}
+
+	torrentEntryNameWithExt := torrentEntry.Name + filepath.Ext(mediaFile.OriginalFilePath)
+	if torrentEntryNameWithExt == mediaFile.OriginalFilePath {
+		return true
+	}
+
+	for _, torrentEntryFile := range torrentEntry.Files {
+		if torrentEntryFile.Size != mediaFile.Size {
+			continue
+		}
+		if torrentEntryFile.Path == mediaFile.OriginalFilePath {
+			return true
+		}
+		torrentFileBase := filepath.Base(torrentEntryFile.Path)
+		if torrentFileBase == mediaFile.OriginalFilePath {
+			return true
 		}
 	}
-	return inventory.LinkedMediaFile{}, false
+	return false
 }
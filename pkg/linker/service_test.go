@@ -69,6 +69,20 @@ func TestService_LinkMedia(t *testing.T) {
 		10,
 	}}
 
+	torrentEntryCrossSeed := torrentEntryOnlyFileMatch
+	torrentEntryCrossSeed.Id = "stc-2"
+	torrentEntryCrossSeed.Trackers = []string{"other-mock-tracker"}
+
+	mediaFileWithDownloadId := mediaFile
+	mediaFileWithDownloadId.DownloadId = "0A1B2C3D4E5F60718293A4B5C6D7E8F901234567"
+	mediaEntryWithDownloadId := domain.MediaEntry{
+		MediaMetadata: mediaMetaData,
+		Files:         []domain.MediaFile{mediaFileWithDownloadId},
+	}
+
+	torrentEntryDownloadIdMatch := torrentEntryNoMatch
+	torrentEntryDownloadIdMatch.Id = "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"
+
 	type args struct {
 		media    []*domain.MediaEntry
 		torrents []*domain.TorrentEntry
@@ -86,7 +100,7 @@ func TestService_LinkMedia(t *testing.T) {
 				[]*domain.TorrentEntry{&torrentEntryNoMatch},
 			},
 			[]inventory.LinkedMedia{{mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, nil}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{}}},
 			}},
 			false,
 		},
@@ -98,7 +112,7 @@ func TestService_LinkMedia(t *testing.T) {
 			},
 			[]inventory.LinkedMedia{{
 				mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, &torrentEntry}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{&torrentEntry}}},
 			}},
 			false,
 		},
@@ -110,7 +124,7 @@ func TestService_LinkMedia(t *testing.T) {
 			},
 			[]inventory.LinkedMedia{{
 				mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, &torrentEntryWithoutExt}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{&torrentEntryWithoutExt}}},
 			}},
 			false,
 		},
@@ -122,7 +136,7 @@ func TestService_LinkMedia(t *testing.T) {
 			},
 			[]inventory.LinkedMedia{{
 				mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, &torrentEntryOnlyFileMatch}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{&torrentEntryOnlyFileMatch}}},
 			}},
 			false,
 		},
@@ -134,7 +148,55 @@ func TestService_LinkMedia(t *testing.T) {
 			},
 			[]inventory.LinkedMedia{{
 				mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, &torrentEntryOnlyFileMatchWithFullPath}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{&torrentEntryOnlyFileMatchWithFullPath}}},
+			}},
+			false,
+		},
+		{
+			"media linked with torrent entry - download id match",
+			args{
+				[]*domain.MediaEntry{&mediaEntryWithDownloadId},
+				[]*domain.TorrentEntry{&torrentEntryNoMatch, &torrentEntryDownloadIdMatch},
+			},
+			[]inventory.LinkedMedia{{
+				mediaMetaData,
+				[]inventory.LinkedMediaFile{{mediaFileWithDownloadId, []*domain.TorrentEntry{&torrentEntryDownloadIdMatch}}},
+			}},
+			false,
+		},
+		{
+			"media linked with torrent entries - download id match first",
+			args{
+				[]*domain.MediaEntry{&mediaEntryWithDownloadId},
+				[]*domain.TorrentEntry{&torrentEntry, &torrentEntryDownloadIdMatch},
+			},
+			[]inventory.LinkedMedia{{
+				mediaMetaData,
+				[]inventory.LinkedMediaFile{{mediaFileWithDownloadId, []*domain.TorrentEntry{&torrentEntryDownloadIdMatch, &torrentEntry}}},
+			}},
+			false,
+		},
+		{
+			"media linked with torrent entries - cross-seeded on multiple trackers",
+			args{
+				[]*domain.MediaEntry{&mediaEntry},
+				[]*domain.TorrentEntry{&torrentEntry, &torrentEntryNoMatch, &torrentEntryCrossSeed},
+			},
+			[]inventory.LinkedMedia{{
+				mediaMetaData,
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{&torrentEntry, &torrentEntryCrossSeed}}},
+			}},
+			false,
+		},
+		{
+			"media linked with torrent entry - name match as fallback for unknown download id",
+			args{
+				[]*domain.MediaEntry{&mediaEntryWithDownloadId},
+				[]*domain.TorrentEntry{&torrentEntry},
+			},
+			[]inventory.LinkedMedia{{
+				mediaMetaData,
+				[]inventory.LinkedMediaFile{{mediaFileWithDownloadId, []*domain.TorrentEntry{&torrentEntry}}},
 			}},
 			false,
 		},
@@ -146,7 +208,7 @@ func TestService_LinkMedia(t *testing.T) {
 			},
 			[]inventory.LinkedMedia{{
 				mediaMetaData,
-				[]inventory.LinkedMediaFile{{mediaFile, nil}},
+				[]inventory.LinkedMediaFile{{mediaFile, []*domain.TorrentEntry{}}},
 			}},
 			false,
 		},
@@ -0,0 +1,46 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+var _ domain.MediaSource = (*DegradedMediaSource)(nil)
+
+// DegradedMediaSource stands in for a media source that could not be set up. It delegates to the connected source once
+// it has been reconnected in the background. Until then, every call fails with domain.ErrSourceDegraded.
+type DegradedMediaSource struct {
+	name      string
+	mediaType domain.MediaType
+	source    *domain.DegradedSource[domain.MediaSource]
+}
+
+func NewDegradedMediaSource(ctx context.Context, name string, mediaType domain.MediaType, connectErr error, connect func() (domain.MediaSource, error), retryInterval time.Duration) *DegradedMediaSource {
+	return &DegradedMediaSource{name, mediaType, domain.NewDegradedSource(ctx, name, connectErr, connect, retryInterval)}
+}
+
+func (degradedSource *DegradedMediaSource) GetMedia(ctx context.Context) ([]domain.MediaEntry, error) {
+	source, err := degradedSource.source.Get()
+	if err != nil {
+		return nil, err
+	}
+	return source.GetMedia(ctx)
+}
+
+func (degradedSource *DegradedMediaSource) SupportedMediaType() domain.MediaType {
+	return degradedSource.mediaType
+}
+
+func (degradedSource *DegradedMediaSource) DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error {
+	source, err := degradedSource.source.Get()
+	if err != nil {
+		return err
+	}
+	return source.DeleteMediaFiles(ctx, fileIds, stopParentMonitoring)
+}
+
+func (degradedSource *DegradedMediaSource) Name() string {
+	return degradedSource.name
+}
@@ -0,0 +1,78 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golift.io/starr"
+)
+
+// historyPageSize is the number of history records requested per page from the *arr apis.
+const historyPageSize = 1000
+
+// downloadIdLookup maps imported files to the id of the download they were imported from. Files are looked up by their
+// file id first and by their imported path as fallback, as older *arr versions do not record the file id.
+type downloadIdLookup struct {
+	byFileId map[int64]string
+	byPath   map[string]string
+}
+
+func newDownloadIdLookup() downloadIdLookup {
+	return downloadIdLookup{make(map[int64]string), make(map[string]string)}
+}
+
+// add records the download id of an import. Records have to be added from newest to oldest so that re-imports resolve
+// to the latest download.
+func (l downloadIdLookup) add(downloadId string, rawFileId string, importedPath string) {
+	if downloadId == "" {
+		return
+	}
+	if fileId, err := strconv.ParseInt(rawFileId, 10, 64); err == nil && fileId != 0 {
+		if _, ok := l.byFileId[fileId]; !ok {
+			l.byFileId[fileId] = downloadId
+		}
+	}
+	if importedPath != "" {
+		if _, ok := l.byPath[importedPath]; !ok {
+			l.byPath[importedPath] = downloadId
+		}
+	}
+}
+
+func (l downloadIdLookup) get(fileId int64, path string) string {
+	if downloadId, ok := l.byFileId[fileId]; ok {
+		return downloadId
+	}
+	return l.byPath[path]
+}
+
+// historyImport is an import recorded in the history of an *arr.
+type historyImport struct {
+	downloadId   string
+	fileId       string
+	importedPath string
+}
+
+// getDownloadIds returns the download ids of all imports recorded in the history of an *arr. getPage returns the imports
+// of the requested history page along with the total number of records.
+func getDownloadIds(ctx context.Context, getPage func(ctx context.Context, req *starr.PageReq) ([]historyImport, int, error)) (downloadIdLookup, error) {
+	downloadIds := newDownloadIdLookup()
+	for page := 1; ; page++ {
+		imports, totalRecords, err := getPage(ctx, &starr.PageReq{
+			Page:     page,
+			PageSize: historyPageSize,
+			SortKey:  "date",
+			SortDir:  starr.SortDescend,
+		})
+		if err != nil {
+			return downloadIds, fmt.Errorf("could not get history page %d: %w", page, err)
+		}
+		for _, historyImport := range imports {
+			downloadIds.add(historyImport.downloadId, historyImport.fileId, historyImport.importedPath)
+		}
+		if len(imports) == 0 || page*historyPageSize >= totalRecords {
+			return downloadIds, nil
+		}
+	}
+}
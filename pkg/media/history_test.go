@@ -0,0 +1,38 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_downloadIdLookup(t *testing.T) {
+	downloadIds := newDownloadIdLookup()
+	// records are added from newest to oldest
+	downloadIds.add("newest", "1", "/media/movie.mkv")
+	downloadIds.add("older", "1", "/media/movie.mkv")
+	downloadIds.add("", "2", "/media/other.mkv")
+	downloadIds.add("path-only", "", "/media/legacy.mkv")
+	downloadIds.add("invalid-file-id", "not-a-number", "/media/invalid.mkv")
+	downloadIds.add("zero-file-id", "0", "")
+
+	tests := []struct {
+		name   string
+		fileId int64
+		path   string
+		want   string
+	}{
+		{"newest import wins", 1, "/media/movie.mkv", "newest"},
+		{"file id before path", 1, "/media/legacy.mkv", "newest"},
+		{"path as fallback", 3, "/media/legacy.mkv", "path-only"},
+		{"unparsable file id falls back to path", 4, "/media/invalid.mkv", "invalid-file-id"},
+		{"zero file id is not recorded", 0, "", ""},
+		{"empty download id is not recorded", 2, "/media/other.mkv", ""},
+		{"unknown file", 5, "/media/unknown.mkv", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, downloadIds.get(tt.fileId, tt.path))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/util"
+	"golift.io/starr"
+	"golift.io/starr/lidarr"
+)
+
+var _ domain.MediaSource = (*LidarrRetriever)(nil)
+
+type LidarrRetriever struct {
+	name   string
+	client *lidarr.Lidarr
+	appUrl string
+	// parallelism is the maximum number of concurrent track file requests.
+	parallelism int
+	dryRun      bool
+}
+
+const lidarrAlbumMonitorEndpoint = lidarr.APIver + "/album/monitor"
+
+func NewLidarrRetriever(name string, appUrl string, apiKey string, timeout time.Duration, parallelism int, dryRun bool) (*LidarrRetriever, error) {
+	config := starr.New(apiKey, appUrl, timeout)
+	client := lidarr.New(config)
+	_, err := client.GetSystemStatusContext(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("could not get lidarr system status: %w", err)
+	}
+	return &LidarrRetriever{name, client, appUrl, max(parallelism, 1), dryRun}, nil
+}
+
+// GetMedia returns an entry per artist. The track files are grouped by their album which takes the place of the season
+// of a series.
+func (r *LidarrRetriever) GetMedia(ctx context.Context) ([]domain.MediaEntry, error) {
+	artists, err := r.client.GetArtistContext(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get lidarr artists: %w", err)
+	}
+	albums, err := r.client.GetAlbumContext(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get lidarr albums: %w", err)
+	}
+	albumTitles := make(map[int64]string, len(albums))
+	for _, album := range albums {
+		albumTitles[album.ID] = album.Title
+	}
+	downloadIds, err := getDownloadIds(ctx, r.getHistoryImports)
+	if err != nil {
+		slog.Warn("Could not get lidarr download history. Linking media by file names only.", "instance", r.name, "error", err)
+	}
+	artists = slices.DeleteFunc(artists, func(artist *lidarr.Artist) bool {
+		return artist.Statistics == nil || artist.Statistics.SizeOnDisk == 0
+	})
+	// lidarr only supports retrieving the track files of a single artist per request
+	artistTrackFiles, err := util.MapConcurrently(ctx, artists, r.parallelism, func(ctx context.Context, artist *lidarr.Artist) ([]*lidarr.TrackFile, error) {
+		trackFiles, err := r.client.GetTrackFilesForArtistContext(ctx, artist.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get artist track files: %w", err)
+		}
+		return trackFiles, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	mediaList := make([]domain.MediaEntry, 0, len(artists))
+	for i, artist := range artists {
+		trackFiles := artistTrackFiles[i]
+		parts := make([]domain.MediaFile, 0, len(trackFiles))
+		for _, trackFile := range trackFiles {
+			parts = append(parts, domain.MediaFile{
+				Id:               trackFile.ID,
+				Season:           int(trackFile.AlbumID),
+				SeasonTitle:      albumTitles[trackFile.AlbumID],
+				OriginalFilePath: filepath.Base(trackFile.Path),
+				Path:             trackFile.Path,
+				Size:             trackFile.Size,
+				DownloadId:       downloadIds.get(trackFile.ID, trackFile.Path),
+			})
+		}
+		media := domain.MediaEntry{
+			MediaMetadata: domain.MediaMetadata{
+				Instance: r.name,
+				Id:       artist.ID,
+				Type:     domain.MediaTypeMusic,
+				Title:    artist.ArtistName,
+				Url:      path.Join(r.appUrl, fmt.Sprintf("artist/%s", artist.ForeignArtistID)),
+				Added:    artist.Added,
+			},
+			Files: parts,
+		}
+		mediaList = append(mediaList, media)
+	}
+	return mediaList, nil
+}
+
+// getHistoryImports returns a page of the imports recorded in the lidarr history. Lidarr does not record the track file
+// id, so files are only matched by their imported path.
+func (r *LidarrRetriever) getHistoryImports(ctx context.Context, req *starr.PageReq) ([]historyImport, int, error) {
+	req.Filter = lidarr.FilterTrackFileImported
+	history, err := r.client.GetHistoryPageContext(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports := make([]historyImport, 0, len(history.Records))
+	for _, record := range history.Records {
+		imports = append(imports, historyImport{record.DownloadID, "", record.Data.ImportedPath})
+	}
+	return imports, history.TotalRecords, nil
+}
+
+func (r *LidarrRetriever) DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error {
+	albumIds := make([]int64, 0)
+	if stopParentMonitoring {
+		trackFiles, err := r.client.GetTrackFilesContext(ctx, fileIds)
+		if err != nil {
+			return fmt.Errorf("could not get lidarr track files (file ids: %+v): %w", fileIds, err)
+		}
+		albumIds = getParentIds(trackFiles, func(trackFile *lidarr.TrackFile) int64 {
+			return trackFile.AlbumID
+		})
+	}
+	if r.dryRun {
+		slog.Info("[DRY RUN] Skipping delete lidarr track files.", "fileIds", fileIds)
+	} else if err := r.client.DeleteTrackFilesContext(ctx, fileIds); err != nil {
+		return fmt.Errorf("could not bulk delete track files from lidarr: %w", err)
+	}
+	if len(albumIds) == 0 {
+		return nil
+	}
+	if r.dryRun {
+		slog.Info("[DRY RUN] Skipping lidarr stop monitoring albums.", "albumIds", albumIds)
+		return nil
+	}
+	var albums []*lidarr.Album
+	if err := stopMonitoring(ctx, r.client, lidarrAlbumMonitorEndpoint, "albumIds", albumIds, &albums); err != nil {
+		return fmt.Errorf("could not update monitoring status of albums %v: %w", albumIds, err)
+	}
+	return nil
+}
+
+func (r *LidarrRetriever) SupportedMediaType() domain.MediaType {
+	return domain.MediaTypeMusic
+}
+
+func (r *LidarrRetriever) Name() string {
+	return r.name
+}
@@ -0,0 +1,136 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeLidarrTrackFiles = []map[string]any{
+	{"id": 100, "artistId": 1, "albumId": 10, "path": "/music/Some Artist/First Album/01 - Intro.flac", "size": 1000},
+	{"id": 101, "artistId": 1, "albumId": 10, "path": "/music/Some Artist/First Album/02 - Song.flac", "size": 1000},
+	{"id": 102, "artistId": 1, "albumId": 11, "path": "/music/Some Artist/Second Album/01 - Other Song.flac", "size": 1000},
+}
+
+func newFakeLidarrServer(t *testing.T) (*fakeArrServer, string) {
+	return newFakeArrServer(t, map[string]any{
+		"GET /api/v1/system/status": map[string]any{"appName": "Lidarr"},
+		"GET /api/v1/artist": []map[string]any{
+			{"id": 1, "artistName": "Some Artist", "foreignArtistId": "mbid-1", "added": "2026-01-01T00:00:00Z", "statistics": map[string]any{"sizeOnDisk": 3000}},
+			{"id": 2, "artistName": "Artist Without Files", "foreignArtistId": "mbid-2", "statistics": map[string]any{"sizeOnDisk": 0}},
+		},
+		"GET /api/v1/album": []map[string]any{{"id": 10, "title": "First Album"}, {"id": 11, "title": "Second Album"}},
+		"GET /api/v1/history": map[string]any{"page": 1, "totalRecords": 1, "records": []map[string]any{{
+			"downloadId": "download-1",
+			"data":       map[string]any{"importedPath": "/music/Some Artist/Second Album/01 - Other Song.flac"},
+		}}},
+		"GET /api/v1/trackfile": func(query url.Values) any {
+			trackFiles := make([]map[string]any, 0)
+			for _, trackFile := range fakeLidarrTrackFiles {
+				if query.Get("artistId") == strconv.Itoa(trackFile["artistId"].(int)) ||
+					slices.Contains(query["trackFileIds"], strconv.Itoa(trackFile["id"].(int))) {
+					trackFiles = append(trackFiles, trackFile)
+				}
+			}
+			return trackFiles
+		},
+		"DELETE /api/v1/trackfile/bulk": nil,
+		"PUT /api/v1/album/monitor":     []any{},
+	})
+}
+
+func TestLidarrRetriever_GetMedia(t *testing.T) {
+	_, appUrl := newFakeLidarrServer(t)
+	retriever, err := NewLidarrRetriever("lidarr", appUrl, "secret", time.Minute, 2, false)
+	if err != nil {
+		t.Fatalf("NewLidarrRetriever() error = %v", err)
+	}
+
+	mediaList, err := retriever.GetMedia(context.Background())
+	if err != nil {
+		t.Fatalf("GetMedia() error = %v", err)
+	}
+	assert.Equal(t, []domain.MediaEntry{{
+		MediaMetadata: domain.MediaMetadata{
+			Instance: "lidarr",
+			Id:       1,
+			Type:     domain.MediaTypeMusic,
+			Title:    "Some Artist",
+			Url:      path.Join(appUrl, "artist/mbid-1"),
+			Added:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Files: []domain.MediaFile{{
+			Id:               100,
+			Season:           10,
+			SeasonTitle:      "First Album",
+			OriginalFilePath: "01 - Intro.flac",
+			Path:             "/music/Some Artist/First Album/01 - Intro.flac",
+			Size:             1000,
+		}, {
+			Id:               101,
+			Season:           10,
+			SeasonTitle:      "First Album",
+			OriginalFilePath: "02 - Song.flac",
+			Path:             "/music/Some Artist/First Album/02 - Song.flac",
+			Size:             1000,
+		}, {
+			Id:               102,
+			Season:           11,
+			SeasonTitle:      "Second Album",
+			OriginalFilePath: "01 - Other Song.flac",
+			Path:             "/music/Some Artist/Second Album/01 - Other Song.flac",
+			Size:             1000,
+			DownloadId:       "download-1",
+		}},
+	}}, mediaList)
+}
+
+func TestLidarrRetriever_DeleteMediaFiles(t *testing.T) {
+	tests := []struct {
+		name                 string
+		stopParentMonitoring bool
+		dryRun               bool
+		wantDeleted          bool
+		wantMonitorBody      string
+	}{
+		{"delete files", false, false, true, ""},
+		{"delete files and stop monitoring albums", true, false, true, `{"albumIds":[10,11],"monitored":false}`},
+		{"dry run", true, true, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, appUrl := newFakeLidarrServer(t)
+			retriever, err := NewLidarrRetriever("lidarr", appUrl, "secret", time.Minute, 1, tt.dryRun)
+			if err != nil {
+				t.Fatalf("NewLidarrRetriever() error = %v", err)
+			}
+
+			err = retriever.DeleteMediaFiles(context.Background(), []int64{100, 101, 102}, tt.stopParentMonitoring)
+			if err != nil {
+				t.Fatalf("DeleteMediaFiles() error = %v", err)
+			}
+			deleteRequests := server.getRequests(http.MethodDelete, "/api/v1/trackfile/bulk")
+			if tt.wantDeleted {
+				assert.Len(t, deleteRequests, 1)
+				assert.JSONEq(t, `{"trackFileIDs":[100,101,102]}`, deleteRequests[0].body)
+			} else {
+				assert.Empty(t, deleteRequests)
+			}
+			monitorRequests := server.getRequests(http.MethodPut, "/api/v1/album/monitor")
+			if tt.wantMonitorBody != "" {
+				assert.Len(t, monitorRequests, 1)
+				assert.JSONEq(t, tt.wantMonitorBody, monitorRequests[0].body)
+			} else {
+				assert.Empty(t, monitorRequests)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package media
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,22 +15,22 @@ import (
 var _ domain.MediaSourceManager = (*DefaultMediaManager)(nil)
 
 type DefaultMediaManager struct {
-	Entries    map[domain.MediaType][]domain.MediaEntry
+	Entries    map[string][]domain.MediaEntry
 	entryLock  *sync.Mutex
-	retrievers map[domain.MediaType]domain.MediaSource
+	retrievers map[string]domain.MediaSource
 }
 
 func NewDefaultMediaManager(retrievers ...domain.MediaSource) *DefaultMediaManager {
-	manager := &DefaultMediaManager{nil, &sync.Mutex{}, make(map[domain.MediaType]domain.MediaSource)}
+	manager := &DefaultMediaManager{nil, &sync.Mutex{}, make(map[string]domain.MediaSource)}
 	for _, retriever := range retrievers {
-		manager.retrievers[retriever.SupportedMediaType()] = retriever
+		manager.retrievers[retriever.Name()] = retriever
 	}
 	return manager
 }
 
-func (manager *DefaultMediaManager) GetMedia() ([]*domain.MediaEntry, error) {
+func (manager *DefaultMediaManager) GetMedia(ctx context.Context) ([]*domain.MediaEntry, error) {
 	if manager.Entries == nil {
-		if err := manager.RefreshCache(); err != nil {
+		if err := manager.RefreshCache(ctx); err != nil && !errors.Is(err, domain.ErrSourceDegraded) {
 			return nil, err
 		}
 	}
@@ -43,44 +44,64 @@ func (manager *DefaultMediaManager) GetMedia() ([]*domain.MediaEntry, error) {
 	return mediaList, nil
 }
 
-func (manager *DefaultMediaManager) DeleteMediaFiles(mediaType domain.MediaType, fileIds []int64, stopParentMonitoring bool) error {
+func (manager *DefaultMediaManager) DeleteMediaFiles(ctx context.Context, instance string, fileIds []int64, stopParentMonitoring bool) error {
 	manager.entryLock.Lock()
 	defer manager.entryLock.Unlock()
-	retriever, ok := manager.retrievers[mediaType]
+	retriever, ok := manager.retrievers[instance]
 	if !ok {
-		return fmt.Errorf("could not find retriever for media type %q", mediaType)
+		return fmt.Errorf("could not find retriever for media instance %q", instance)
 	}
-	return retriever.DeleteMediaFiles(fileIds, stopParentMonitoring)
+	return retriever.DeleteMediaFiles(ctx, fileIds, stopParentMonitoring)
 }
 
-func (manager *DefaultMediaManager) RefreshCache() error {
+// RefreshCache fetches the media entries of all instances. Instances that fail keep their previously known entries. If
+// all of them failed because they could not be reached, the returned error wraps domain.ErrSourceDegraded.
+func (manager *DefaultMediaManager) RefreshCache(ctx context.Context) error {
 	manager.entryLock.Lock()
 	defer manager.entryLock.Unlock()
 	entryLock := sync.Mutex{}
-	manager.Entries = make(map[domain.MediaType][]domain.MediaEntry)
+	previousEntries := manager.Entries
+	manager.Entries = make(map[string][]domain.MediaEntry)
 	errChan := make(chan error)
 	defer close(errChan)
-	for mediaType, retriever := range manager.retrievers {
+	for instance, retriever := range manager.retrievers {
 		go func() {
-			slog.Debug("Refreshing media cache", "type", mediaType)
-			mediaEntries, err := retriever.GetMedia()
+			slog.Debug("Refreshing media cache", "instance", instance)
+			mediaEntries, err := retriever.GetMedia(ctx)
 			if err == nil {
 				entryLock.Lock()
 				defer entryLock.Unlock()
-				manager.Entries[mediaType] = mediaEntries
+				manager.Entries[instance] = mediaEntries
 			}
 			if err != nil {
-				err = fmt.Errorf("could not get media entries media cache for type %q: %w", mediaType, err)
+				entryLock.Lock()
+				defer entryLock.Unlock()
+				if entries, ok := previousEntries[instance]; ok {
+					manager.Entries[instance] = entries
+				}
+				if domain.IsConnectionError(err) {
+					err = fmt.Errorf("%w: could not get media entries for instance %q: %w", domain.ErrSourceDegraded, instance, err)
+				} else {
+					err = fmt.Errorf("could not get media entries for instance %q: %w", instance, err)
+				}
 			}
 			errChan <- err
-			slog.Debug("Refreshed media cache", "type", mediaType)
+			slog.Debug("Refreshed media cache", "instance", instance)
 		}()
 	}
-	var err error
+	// the degraded errors are only returned on their own, so that any other error fails the refresh
+	var err, degradedErr error
 	for i := 0; i < len(manager.retrievers); i++ {
-		err = errors.Join(err, <-errChan)
+		if retrieverErr := <-errChan; errors.Is(retrieverErr, domain.ErrSourceDegraded) {
+			degradedErr = errors.Join(degradedErr, retrieverErr)
+		} else {
+			err = errors.Join(err, retrieverErr)
+		}
+	}
+	if err != nil {
+		return err
 	}
-	return nil
+	return degradedErr
 }
 
 func (manager *DefaultMediaManager) SaveCache(writer io.Writer) error {
@@ -92,6 +113,6 @@ func (manager *DefaultMediaManager) SaveCache(writer io.Writer) error {
 func (manager *DefaultMediaManager) LoadCache(reader io.ReadSeeker) error {
 	manager.entryLock.Lock()
 	defer manager.entryLock.Unlock()
-	manager.Entries = make(map[domain.MediaType][]domain.MediaEntry)
+	manager.Entries = make(map[string][]domain.MediaEntry)
 	return json.NewDecoder(reader).Decode(&manager.Entries)
 }
@@ -0,0 +1,60 @@
+package media
+
+import (
+	"context"
+	"log/slog"
+
+	"golift.io/starr"
+)
+
+// metadataSource is implemented by the retrievers of *arrs supporting tags and quality profiles.
+type metadataSource interface {
+	Name() string
+	getTags(ctx context.Context) ([]*starr.Tag, error)
+	getQualityProfileNames(ctx context.Context) (map[int64]string, error)
+}
+
+// getMetadataLookups returns the labels of all tags and the names of all quality profiles by their ids. Both are empty
+// if they cannot be retrieved as the media can still be listed without them.
+func getMetadataLookups(ctx context.Context, source metadataSource) (tagLookup, map[int64]string) {
+	tags, err := source.getTags(ctx)
+	if err != nil {
+		slog.Warn("Could not get tags.", "instance", source.Name(), "error", err)
+	}
+	qualityProfiles, err := source.getQualityProfileNames(ctx)
+	if err != nil {
+		slog.Warn("Could not get quality profiles.", "instance", source.Name(), "error", err)
+		qualityProfiles = make(map[int64]string)
+	}
+	return newTagLookup(tags), qualityProfiles
+}
+
+// tagLookup maps the ids of *arr tags to their labels.
+type tagLookup map[int]string
+
+func newTagLookup(tags []*starr.Tag) tagLookup {
+	lookup := make(tagLookup, len(tags))
+	for _, tag := range tags {
+		lookup[tag.ID] = tag.Label
+	}
+	return lookup
+}
+
+// get returns the labels of the given tag ids. Unknown ids are skipped.
+func (l tagLookup) get(ids []int) []string {
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if label, ok := l[id]; ok {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// getQuality returns the quality name and resolution of a file or empty values if the *arr does not report them.
+func getQuality(quality *starr.Quality) (string, int) {
+	if quality == nil || quality.Quality == nil {
+		return "", 0
+	}
+	return quality.Quality.Name, quality.Quality.Resolution
+}
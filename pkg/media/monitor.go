@@ -0,0 +1,36 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"golift.io/starr"
+)
+
+// getParentIds returns the distinct ids of the parents of the given files, e.g. their albums or books.
+func getParentIds[F any](files []F, getParentId func(file F) int64) []int64 {
+	parentIds := make([]int64, 0)
+	for _, file := range files {
+		if parentId := getParentId(file); !slices.Contains(parentIds, parentId) {
+			parentIds = append(parentIds, parentId)
+		}
+	}
+	return parentIds
+}
+
+// stopMonitoring unmonitors the given parents of files using the bulk monitor endpoint of an *arr which expects the ids
+// in the field idsKey. The updated parents are decoded into output.
+func stopMonitoring(ctx context.Context, client starr.APIer, endpoint string, idsKey string, ids []int64, output any) error {
+	payloadEncoded, err := json.Marshal(map[string]any{idsKey: ids, "monitored": false})
+	if err != nil {
+		return fmt.Errorf("could not encode monitor payload: %w", err)
+	}
+	req := starr.Request{URI: endpoint, Body: bytes.NewReader(payloadEncoded)}
+	if err = client.PutInto(ctx, req, output); err != nil {
+		return fmt.Errorf("api.Put(%s): %w", &req, err)
+	}
+	return nil
+}
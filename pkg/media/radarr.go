@@ -1,11 +1,14 @@
 package media
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
 	"golift.io/starr"
@@ -15,29 +18,40 @@ import (
 var _ domain.MediaSource = (*RadarrRetriever)(nil)
 
 type RadarrRetriever struct {
+	name   string
 	client *radarr.Radarr
 	appUrl string
 	dryRun bool
 }
 
-func NewRadarrRetriever(appUrl string, apiKey string, dryRun bool) (*RadarrRetriever, error) {
-	starrConfig := starr.New(apiKey, appUrl, 0)
+const radarrMovieEndpoint = radarr.APIver + "/movie"
+
+// radarrMovie adds the root folder which is reported by radarr but not decoded by the radarr client.
+type radarrMovie struct {
+	radarr.Movie
+	RootFolderPath string `json:"rootFolderPath"`
+}
+
+func NewRadarrRetriever(name string, appUrl string, apiKey string, timeout time.Duration, dryRun bool) (*RadarrRetriever, error) {
+	starrConfig := starr.New(apiKey, appUrl, timeout)
 	client := radarr.New(starrConfig)
-	_, err := client.GetSystemStatus()
+	_, err := client.GetSystemStatusContext(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("could not get radarr system status: %w", err)
 	}
-	return &RadarrRetriever{client, appUrl, dryRun}, nil
+	return &RadarrRetriever{name, client, appUrl, dryRun}, nil
 }
 
-func (r *RadarrRetriever) GetMedia() ([]domain.MediaEntry, error) {
-	movies, err := r.client.GetMovie(&radarr.GetMovie{
-		TMDBID:             0,
-		ExcludeLocalCovers: true,
-	})
+func (r *RadarrRetriever) GetMedia(ctx context.Context) ([]domain.MediaEntry, error) {
+	movies, err := r.getMovies(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("could not get radarr movies: %w", err)
 	}
+	downloadIds, err := getDownloadIds(ctx, r.getHistoryImports)
+	if err != nil {
+		slog.Warn("Could not get radarr download history. Linking media by file names only.", "instance", r.name, "error", err)
+	}
+	tags, qualityProfiles := getMetadataLookups(ctx, r)
 	var mappedMovies []domain.MediaEntry
 	for _, movie := range movies {
 		if !movie.HasFile {
@@ -50,19 +64,30 @@ func (r *RadarrRetriever) GetMedia() ([]domain.MediaEntry, error) {
 			originalFilePath = movie.MovieFile.Path
 		}
 		originalFilePath = filepath.Base(originalFilePath)
+		quality, resolution := getQuality(movie.MovieFile.Quality)
 		mappedMovies = append(mappedMovies, domain.MediaEntry{
 			MediaMetadata: domain.MediaMetadata{
-				Id:    movie.ID,
-				Type:  domain.MediaTypeMovie,
-				Title: movie.Title,
-				Url:   path.Join(r.appUrl, fmt.Sprintf("/movie/%d", movie.TmdbID)),
-				Added: movie.Added,
+				Instance:       r.name,
+				Id:             movie.ID,
+				Type:           domain.MediaTypeMovie,
+				Title:          movie.Title,
+				Url:            path.Join(r.appUrl, fmt.Sprintf("/movie/%d", movie.TmdbID)),
+				Added:          movie.Added,
+				Tags:           tags.get(movie.Tags),
+				QualityProfile: qualityProfiles[movie.QualityProfileID],
+				RootFolder:     movie.RootFolderPath,
+				Monitored:      movie.Monitored,
 			},
 			Files: []domain.MediaFile{
 				{
 					Id:               movie.MovieFile.ID,
 					OriginalFilePath: originalFilePath,
+					Path:             movie.MovieFile.Path,
 					Size:             movie.SizeOnDisk,
+					DownloadId:       downloadIds.get(movie.MovieFile.ID, movie.MovieFile.Path),
+					Quality:          quality,
+					Resolution:       resolution,
+					ReleaseGroup:     movie.MovieFile.ReleaseGroup,
 				},
 			},
 		})
@@ -70,8 +95,47 @@ func (r *RadarrRetriever) GetMedia() ([]domain.MediaEntry, error) {
 	return mappedMovies, nil
 }
 
-func (r *RadarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring bool) error {
-	movieFiles, err := r.client.GetMovieFiles(fileIds)
+func (r *RadarrRetriever) getMovies(ctx context.Context) ([]*radarrMovie, error) {
+	req := starr.Request{URI: radarrMovieEndpoint, Query: url.Values{"excludeLocalCovers": {"true"}}}
+	var output []*radarrMovie
+	if err := r.client.GetInto(ctx, req, &output); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, err)
+	}
+	return output, nil
+}
+
+func (r *RadarrRetriever) getTags(ctx context.Context) ([]*starr.Tag, error) {
+	return r.client.GetTagsContext(ctx)
+}
+
+func (r *RadarrRetriever) getQualityProfileNames(ctx context.Context) (map[int64]string, error) {
+	profiles, err := r.client.GetQualityProfilesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	qualityProfiles := make(map[int64]string, len(profiles))
+	for _, profile := range profiles {
+		qualityProfiles[profile.ID] = profile.Name
+	}
+	return qualityProfiles, nil
+}
+
+// getHistoryImports returns a page of the imports recorded in the radarr history.
+func (r *RadarrRetriever) getHistoryImports(ctx context.Context, req *starr.PageReq) ([]historyImport, int, error) {
+	req.Filter = radarr.FilterDownloadFolderImported
+	history, err := r.client.GetHistoryPageContext(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports := make([]historyImport, 0, len(history.Records))
+	for _, record := range history.Records {
+		imports = append(imports, historyImport{record.DownloadID, record.Data.FileID, record.Data.ImportedPath})
+	}
+	return imports, history.TotalRecords, nil
+}
+
+func (r *RadarrRetriever) DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error {
+	movieFiles, err := r.client.GetMovieFilesContext(ctx, fileIds)
 	if err != nil {
 		return fmt.Errorf("could not get radarr movie files (file ids: %+v): %w", fileIds, err)
 	}
@@ -84,13 +148,13 @@ func (r *RadarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring
 			movies[movieFile.MovieID] = struct{}{}
 		}
 	}
-	if err = r.deleteMovieFiles(fileIds); err != nil {
+	if err = r.deleteMovieFiles(ctx, fileIds); err != nil {
 		return fmt.Errorf("could not bulk delete movie files: %w", err)
 	}
 	monitoringUpdateErrors := make([]error, 0)
 	if stopParentMonitoring {
 		for movieId, _ := range movies {
-			if err = r.stopMovieMonitoring(movieId); err != nil {
+			if err = r.stopMovieMonitoring(ctx, movieId); err != nil {
 				monitoringUpdateErrors = append(monitoringUpdateErrors, err)
 			}
 		}
@@ -101,20 +165,20 @@ func (r *RadarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring
 	return nil
 }
 
-func (r *RadarrRetriever) deleteMovieFiles(fileIds []int64) error {
+func (r *RadarrRetriever) deleteMovieFiles(ctx context.Context, fileIds []int64) error {
 	if r.dryRun {
 		slog.Info("[DRY RUN] Skipping radarr movie file deletion.", "fileIds", fileIds)
 		return nil
 	}
-	return r.client.DeleteMovieFiles(fileIds...)
+	return r.client.DeleteMovieFilesContext(ctx, fileIds...)
 }
 
-func (r *RadarrRetriever) stopMovieMonitoring(movieId int64) error {
+func (r *RadarrRetriever) stopMovieMonitoring(ctx context.Context, movieId int64) error {
 	if r.dryRun {
 		slog.Info("[DRY RUN] Skipping radarr stop movie monitoring call.", "movieId", movieId)
 		return nil
 	}
-	_, err := r.client.UpdateMovie(movieId, &radarr.Movie{
+	_, err := r.client.UpdateMovieContext(ctx, movieId, &radarr.Movie{
 		ID:        movieId,
 		Monitored: false,
 	}, false)
@@ -124,3 +188,7 @@ func (r *RadarrRetriever) stopMovieMonitoring(movieId int64) error {
 func (r *RadarrRetriever) SupportedMediaType() domain.MediaType {
 	return domain.MediaTypeMovie
 }
+
+func (r *RadarrRetriever) Name() string {
+	return r.name
+}
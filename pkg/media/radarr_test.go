@@ -0,0 +1,139 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeArrRequest struct {
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+// fakeArrServer answers the requests of the *arr clients with fixed responses by request method and path. Responses may
+// also be functions of the request query. Requests without response fail with 404.
+type fakeArrServer struct {
+	lock      sync.Mutex
+	responses map[string]any
+	requests  []fakeArrRequest
+}
+
+func newFakeArrServer(tb testing.TB, responses map[string]any) (*fakeArrServer, string) {
+	tb.Helper()
+	server := &fakeArrServer{responses: responses}
+	httpServer := httptest.NewServer(server)
+	tb.Cleanup(httpServer.Close)
+	return server, httpServer.URL + "/"
+}
+
+func (server *fakeArrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	server.requests = append(server.requests, fakeArrRequest{r.Method, r.URL.Path, r.URL.RawQuery, string(body)})
+	result, ok := server.responses[r.Method+" "+r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if respond, ok := result.(func(query url.Values) any); ok {
+		result = respond(r.URL.Query())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// getRequests returns the recorded requests with the given method and path.
+func (server *fakeArrServer) getRequests(method string, path string) []fakeArrRequest {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	requests := make([]fakeArrRequest, 0)
+	for _, request := range server.requests {
+		if request.method == method && request.path == path {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func TestRadarrRetriever_GetMedia(t *testing.T) {
+	server, appUrl := newFakeArrServer(t, map[string]any{
+		"GET /api/v3/system/status": map[string]any{"appName": "Radarr"},
+		"GET /api/v3/movie": []map[string]any{{
+			"id":               1,
+			"title":            "Some Movie",
+			"tmdbId":           1234,
+			"path":             "/movies/Some Movie (2020)",
+			"rootFolderPath":   "/movies/",
+			"added":            "2026-01-01T00:00:00Z",
+			"hasFile":          true,
+			"sizeOnDisk":       1000,
+			"monitored":        true,
+			"tags":             []int{1},
+			"qualityProfileId": 2,
+			"movieFile": map[string]any{
+				"id":               10,
+				"path":             "/movies/Some Movie (2020)/Some Movie.mkv",
+				"originalFilePath": "Some.Movie.2020.1080p-GROUP.mkv",
+				"releaseGroup":     "GROUP",
+				"quality":          map[string]any{"quality": map[string]any{"name": "Bluray-1080p", "resolution": 1080}},
+			},
+		}, {
+			"id":      2,
+			"title":   "Missing Movie",
+			"hasFile": false,
+		}},
+		"GET /api/v3/history": map[string]any{"page": 1, "totalRecords": 1, "records": []map[string]any{{
+			"downloadId": "download-1",
+			"data":       map[string]any{"fileId": "10"},
+		}}},
+		"GET /api/v3/tag": []map[string]any{{"id": 1, "label": "keep"}},
+		// quality profiles cannot be retrieved and are left empty
+	})
+	retriever, err := NewRadarrRetriever("radarr", appUrl, "secret", time.Minute, false)
+	if err != nil {
+		t.Fatalf("NewRadarrRetriever() error = %v", err)
+	}
+
+	mediaList, err := retriever.GetMedia(context.Background())
+	if err != nil {
+		t.Fatalf("GetMedia() error = %v", err)
+	}
+	assert.Equal(t, []domain.MediaEntry{{
+		MediaMetadata: domain.MediaMetadata{
+			Instance:   "radarr",
+			Id:         1,
+			Type:       domain.MediaTypeMovie,
+			Title:      "Some Movie",
+			Url:        path.Join(appUrl, "/movie/1234"),
+			Added:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Tags:       []string{"keep"},
+			RootFolder: "/movies/",
+			Monitored:  true,
+		},
+		Files: []domain.MediaFile{{
+			Id:               10,
+			OriginalFilePath: "Some.Movie.2020.1080p-GROUP.mkv",
+			Path:             "/movies/Some Movie (2020)/Some Movie.mkv",
+			Size:             1000,
+			DownloadId:       "download-1",
+			Quality:          "Bluray-1080p",
+			Resolution:       1080,
+			ReleaseGroup:     "GROUP",
+		}},
+	}}, mediaList)
+	assert.Equal(t, "excludeLocalCovers=true", server.getRequests(http.MethodGet, "/api/v3/movie")[0].query)
+}
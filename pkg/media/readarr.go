@@ -0,0 +1,169 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/util"
+	"golift.io/starr"
+	"golift.io/starr/readarr"
+)
+
+var _ domain.MediaSource = (*ReadarrRetriever)(nil)
+
+type ReadarrRetriever struct {
+	name   string
+	client *readarr.Readarr
+	appUrl string
+	// parallelism is the maximum number of concurrent book file requests.
+	parallelism int
+	dryRun      bool
+}
+
+const (
+	readarrAuthorEndpoint      = readarr.APIver + "/author"
+	readarrBookMonitorEndpoint = readarr.APIver + "/book/monitor"
+)
+
+func NewReadarrRetriever(name string, appUrl string, apiKey string, timeout time.Duration, parallelism int, dryRun bool) (*ReadarrRetriever, error) {
+	config := starr.New(apiKey, appUrl, timeout)
+	client := readarr.New(config)
+	_, err := client.GetSystemStatusContext(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("could not get readarr system status: %w", err)
+	}
+	return &ReadarrRetriever{name, client, appUrl, max(parallelism, 1), dryRun}, nil
+}
+
+// GetMedia returns an entry per author. The book files are grouped by their book which takes the place of the season
+// of a series.
+func (r *ReadarrRetriever) GetMedia(ctx context.Context) ([]domain.MediaEntry, error) {
+	authors, err := r.getAuthors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get readarr authors: %w", err)
+	}
+	books, err := r.client.GetBookContext(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get readarr books: %w", err)
+	}
+	bookTitles := make(map[int64]string, len(books))
+	for _, book := range books {
+		bookTitles[book.ID] = book.Title
+	}
+	downloadIds, err := getDownloadIds(ctx, r.getHistoryImports)
+	if err != nil {
+		slog.Warn("Could not get readarr download history. Linking media by file names only.", "instance", r.name, "error", err)
+	}
+	authors = slices.DeleteFunc(authors, func(author *readarr.Author) bool {
+		return author.Statistics == nil || author.Statistics.SizeOnDisk == 0
+	})
+	// readarr only supports retrieving the book files of a single author per request
+	authorBookFiles, err := util.MapConcurrently(ctx, authors, r.parallelism, func(ctx context.Context, author *readarr.Author) ([]*readarr.BookFile, error) {
+		bookFiles, err := r.client.GetBookFilesForAuthorContext(ctx, author.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get author book files: %w", err)
+		}
+		return bookFiles, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	mediaList := make([]domain.MediaEntry, 0, len(authors))
+	for i, author := range authors {
+		bookFiles := authorBookFiles[i]
+		parts := make([]domain.MediaFile, 0, len(bookFiles))
+		for _, bookFile := range bookFiles {
+			parts = append(parts, domain.MediaFile{
+				Id:               bookFile.ID,
+				Season:           int(bookFile.BookID),
+				SeasonTitle:      bookTitles[bookFile.BookID],
+				OriginalFilePath: filepath.Base(bookFile.Path),
+				Path:             bookFile.Path,
+				Size:             int64(bookFile.Size),
+				DownloadId:       downloadIds.get(bookFile.ID, bookFile.Path),
+			})
+		}
+		media := domain.MediaEntry{
+			MediaMetadata: domain.MediaMetadata{
+				Instance: r.name,
+				Id:       author.ID,
+				Type:     domain.MediaTypeBook,
+				Title:    author.AuthorName,
+				Url:      path.Join(r.appUrl, fmt.Sprintf("author/%s", author.TitleSlug)),
+				Added:    author.Added,
+			},
+			Files: parts,
+		}
+		mediaList = append(mediaList, media)
+	}
+	return mediaList, nil
+}
+
+// getAuthors returns all authors as the readarr client only supports retrieving single authors.
+func (r *ReadarrRetriever) getAuthors(ctx context.Context) ([]*readarr.Author, error) {
+	req := starr.Request{URI: readarrAuthorEndpoint}
+	var output []*readarr.Author
+	if err := r.client.GetInto(ctx, req, &output); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, err)
+	}
+	return output, nil
+}
+
+// getHistoryImports returns a page of the imports recorded in the readarr history. Readarr does not record the book file
+// id, so files are only matched by their imported path.
+func (r *ReadarrRetriever) getHistoryImports(ctx context.Context, req *starr.PageReq) ([]historyImport, int, error) {
+	req.Filter = readarr.FilterBookFileImported
+	history, err := r.client.GetHistoryPageContext(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports := make([]historyImport, 0, len(history.Records))
+	for _, record := range history.Records {
+		imports = append(imports, historyImport{record.DownloadID, "", record.Data.ImportedPath})
+	}
+	return imports, history.TotalRecords, nil
+}
+
+func (r *ReadarrRetriever) DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error {
+	bookIds := make([]int64, 0)
+	if stopParentMonitoring {
+		bookFiles, err := r.client.GetBookFilesContext(ctx, fileIds)
+		if err != nil {
+			return fmt.Errorf("could not get readarr book files (file ids: %+v): %w", fileIds, err)
+		}
+		bookIds = getParentIds(bookFiles, func(bookFile *readarr.BookFile) int64 {
+			return bookFile.BookID
+		})
+	}
+	if r.dryRun {
+		slog.Info("[DRY RUN] Skipping delete readarr book files.", "fileIds", fileIds)
+	} else if err := r.client.DeleteBookFilesContext(ctx, fileIds); err != nil {
+		return fmt.Errorf("could not bulk delete book files from readarr: %w", err)
+	}
+	if len(bookIds) == 0 {
+		return nil
+	}
+	if r.dryRun {
+		slog.Info("[DRY RUN] Skipping readarr stop monitoring books.", "bookIds", bookIds)
+		return nil
+	}
+	var books []*readarr.Book
+	if err := stopMonitoring(ctx, r.client, readarrBookMonitorEndpoint, "bookIds", bookIds, &books); err != nil {
+		return fmt.Errorf("could not update monitoring status of books %v: %w", bookIds, err)
+	}
+	return nil
+}
+
+func (r *ReadarrRetriever) SupportedMediaType() domain.MediaType {
+	return domain.MediaTypeBook
+}
+
+func (r *ReadarrRetriever) Name() string {
+	return r.name
+}
@@ -0,0 +1,136 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeReadarrBookFiles = []map[string]any{
+	{"id": 100, "authorId": 1, "bookId": 10, "path": "/books/Some Author/First Book/First Book.epub", "size": 1000},
+	{"id": 101, "authorId": 1, "bookId": 10, "path": "/books/Some Author/First Book/First Book.mobi", "size": 1000},
+	{"id": 102, "authorId": 1, "bookId": 11, "path": "/books/Some Author/Second Book/Second Book.epub", "size": 1000},
+}
+
+func newFakeReadarrServer(t *testing.T) (*fakeArrServer, string) {
+	return newFakeArrServer(t, map[string]any{
+		"GET /api/v1/system/status": map[string]any{"appName": "Readarr"},
+		"GET /api/v1/author": []map[string]any{
+			{"id": 1, "authorName": "Some Author", "titleSlug": "some-author", "added": "2026-01-01T00:00:00Z", "statistics": map[string]any{"sizeOnDisk": 3000}},
+			{"id": 2, "authorName": "Author Without Files", "titleSlug": "author-without-files", "statistics": map[string]any{"sizeOnDisk": 0}},
+		},
+		"GET /api/v1/book": []map[string]any{{"id": 10, "title": "First Book"}, {"id": 11, "title": "Second Book"}},
+		"GET /api/v1/history": map[string]any{"page": 1, "totalRecords": 1, "records": []map[string]any{{
+			"downloadId": "download-1",
+			"data":       map[string]any{"importedPath": "/books/Some Author/Second Book/Second Book.epub"},
+		}}},
+		"GET /api/v1/bookfile": func(query url.Values) any {
+			bookFiles := make([]map[string]any, 0)
+			for _, bookFile := range fakeReadarrBookFiles {
+				if query.Get("authorId") == strconv.Itoa(bookFile["authorId"].(int)) ||
+					slices.Contains(query["bookFileIds"], strconv.Itoa(bookFile["id"].(int))) {
+					bookFiles = append(bookFiles, bookFile)
+				}
+			}
+			return bookFiles
+		},
+		"DELETE /api/v1/bookfile/bulk": nil,
+		"PUT /api/v1/book/monitor":     []any{},
+	})
+}
+
+func TestReadarrRetriever_GetMedia(t *testing.T) {
+	_, appUrl := newFakeReadarrServer(t)
+	retriever, err := NewReadarrRetriever("readarr", appUrl, "secret", time.Minute, 2, false)
+	if err != nil {
+		t.Fatalf("NewReadarrRetriever() error = %v", err)
+	}
+
+	mediaList, err := retriever.GetMedia(context.Background())
+	if err != nil {
+		t.Fatalf("GetMedia() error = %v", err)
+	}
+	assert.Equal(t, []domain.MediaEntry{{
+		MediaMetadata: domain.MediaMetadata{
+			Instance: "readarr",
+			Id:       1,
+			Type:     domain.MediaTypeBook,
+			Title:    "Some Author",
+			Url:      path.Join(appUrl, "author/some-author"),
+			Added:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Files: []domain.MediaFile{{
+			Id:               100,
+			Season:           10,
+			SeasonTitle:      "First Book",
+			OriginalFilePath: "First Book.epub",
+			Path:             "/books/Some Author/First Book/First Book.epub",
+			Size:             1000,
+		}, {
+			Id:               101,
+			Season:           10,
+			SeasonTitle:      "First Book",
+			OriginalFilePath: "First Book.mobi",
+			Path:             "/books/Some Author/First Book/First Book.mobi",
+			Size:             1000,
+		}, {
+			Id:               102,
+			Season:           11,
+			SeasonTitle:      "Second Book",
+			OriginalFilePath: "Second Book.epub",
+			Path:             "/books/Some Author/Second Book/Second Book.epub",
+			Size:             1000,
+			DownloadId:       "download-1",
+		}},
+	}}, mediaList)
+}
+
+func TestReadarrRetriever_DeleteMediaFiles(t *testing.T) {
+	tests := []struct {
+		name                 string
+		stopParentMonitoring bool
+		dryRun               bool
+		wantDeleted          bool
+		wantMonitorBody      string
+	}{
+		{"delete files", false, false, true, ""},
+		{"delete files and stop monitoring books", true, false, true, `{"bookIds":[10,11],"monitored":false}`},
+		{"dry run", true, true, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, appUrl := newFakeReadarrServer(t)
+			retriever, err := NewReadarrRetriever("readarr", appUrl, "secret", time.Minute, 1, tt.dryRun)
+			if err != nil {
+				t.Fatalf("NewReadarrRetriever() error = %v", err)
+			}
+
+			err = retriever.DeleteMediaFiles(context.Background(), []int64{100, 101, 102}, tt.stopParentMonitoring)
+			if err != nil {
+				t.Fatalf("DeleteMediaFiles() error = %v", err)
+			}
+			deleteRequests := server.getRequests(http.MethodDelete, "/api/v1/bookfile/bulk")
+			if tt.wantDeleted {
+				assert.Len(t, deleteRequests, 1)
+				assert.JSONEq(t, `{"bookFileIds":[100,101,102]}`, deleteRequests[0].body)
+			} else {
+				assert.Empty(t, deleteRequests)
+			}
+			monitorRequests := server.getRequests(http.MethodPut, "/api/v1/book/monitor")
+			if tt.wantMonitorBody != "" {
+				assert.Len(t, monitorRequests, 1)
+				assert.JSONEq(t, tt.wantMonitorBody, monitorRequests[0].body)
+			} else {
+				assert.Empty(t, monitorRequests)
+			}
+		})
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"path"
 	"path/filepath"
 	"slices"
+	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/util"
 	"golift.io/starr"
 	"golift.io/starr/sonarr"
 )
@@ -20,9 +22,12 @@ import (
 var _ domain.MediaSource = (*SonarrRetriever)(nil)
 
 type SonarrRetriever struct {
+	name   string
 	client *sonarr.Sonarr
 	appUrl string
-	dryRun bool
+	// parallelism is the maximum number of concurrent episode file requests.
+	parallelism int
+	dryRun      bool
 }
 
 const (
@@ -30,46 +35,80 @@ const (
 	sonarrEpisodeFileBulkDeleteEndpoint = sonarrEpisodeFileEndpoint + "/bulk"
 )
 
-func NewSonarrRetriever(appUrl string, apiKey string, dryRun bool) (*SonarrRetriever, error) {
-	config := starr.New(apiKey, appUrl, 0)
+func NewSonarrRetriever(name string, appUrl string, apiKey string, timeout time.Duration, parallelism int, dryRun bool) (*SonarrRetriever, error) {
+	config := starr.New(apiKey, appUrl, timeout)
 	client := sonarr.New(config)
-	_, err := client.GetSystemStatus()
+	_, err := client.GetSystemStatusContext(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("could not get sonarr system status: %w", err)
 	}
-	return &SonarrRetriever{client, appUrl, dryRun}, nil
+	return &SonarrRetriever{name, client, appUrl, max(parallelism, 1), dryRun}, nil
 }
 
-func (r *SonarrRetriever) GetMedia() ([]domain.MediaEntry, error) {
-	seriesList, err := r.client.GetAllSeries()
+func (r *SonarrRetriever) GetMedia(ctx context.Context) ([]domain.MediaEntry, error) {
+	seriesList, err := r.client.GetAllSeriesContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("could not get sonarr series: %w", err)
 	}
-	mediaList := make([]domain.MediaEntry, 0)
-	for _, series := range seriesList {
-		if series.Statistics.SizeOnDisk == 0 {
-			continue
-		}
-		seriesEpisodeFiles, err := r.client.GetSeriesEpisodeFiles(series.ID)
-		if err != nil {
-			return nil, fmt.Errorf("could not get series episode files: %w", err)
+	downloadIds, err := getDownloadIds(ctx, r.getHistoryImports)
+	if err != nil {
+		slog.Warn("Could not get sonarr download history. Linking media by file names only.", "instance", r.name, "error", err)
+	}
+	tags, qualityProfiles := getMetadataLookups(ctx, r)
+	seriesList = slices.DeleteFunc(seriesList, func(series *sonarr.Series) bool {
+		return series.Statistics == nil || series.Statistics.SizeOnDisk == 0
+	})
+	seriesFilesList, err := r.getSeriesFiles(ctx, seriesList)
+	if err != nil {
+		return nil, err
+	}
+	mediaList := make([]domain.MediaEntry, 0, len(seriesList))
+	for i, series := range seriesList {
+		seriesFiles := seriesFilesList[i]
+		fileEpisodes := make(map[int64][]*sonarr.Episode)
+		for _, episode := range seriesFiles.episodes {
+			if episode.EpisodeFileID != 0 {
+				fileEpisodes[episode.EpisodeFileID] = append(fileEpisodes[episode.EpisodeFileID], episode)
+			}
 		}
-		parts := make([]domain.MediaFile, 0, len(seriesEpisodeFiles))
-		for _, seriesEpisodeFile := range seriesEpisodeFiles {
-			parts = append(parts, domain.MediaFile{
+		parts := make([]domain.MediaFile, 0, len(seriesFiles.episodeFiles))
+		for _, seriesEpisodeFile := range seriesFiles.episodeFiles {
+			part := domain.MediaFile{
 				Id:               seriesEpisodeFile.ID,
 				Season:           seriesEpisodeFile.SeasonNumber,
 				OriginalFilePath: filepath.Base(seriesEpisodeFile.RelativePath),
+				Path:             seriesEpisodeFile.Path,
 				Size:             seriesEpisodeFile.Size,
+				DownloadId:       downloadIds.get(seriesEpisodeFile.ID, seriesEpisodeFile.Path),
+				ReleaseGroup:     seriesEpisodeFile.ReleaseGroup,
+			}
+			part.Quality, part.Resolution = getQuality(seriesEpisodeFile.Quality)
+			episodes := fileEpisodes[seriesEpisodeFile.ID]
+			slices.SortFunc(episodes, func(a, b *sonarr.Episode) int {
+				return a.EpisodeNumber - b.EpisodeNumber
 			})
+			for _, episode := range episodes {
+				part.EpisodeNumbers = append(part.EpisodeNumbers, episode.EpisodeNumber)
+			}
+			if len(episodes) > 0 {
+				part.EpisodeTitle = episodes[0].Title
+				part.AirDate = episodes[0].AirDateUtc
+			}
+			parts = append(parts, part)
 		}
 		media := domain.MediaEntry{
 			MediaMetadata: domain.MediaMetadata{
-				Id:    series.ID,
-				Type:  domain.MediaTypeSeries,
-				Title: series.Title,
-				Url:   path.Join(r.appUrl, fmt.Sprintf("series/%s", series.TitleSlug)),
-				Added: series.Added,
+				Instance:       r.name,
+				Id:             series.ID,
+				Type:           domain.MediaTypeSeries,
+				Title:          series.Title,
+				Url:            path.Join(r.appUrl, fmt.Sprintf("series/%s", series.TitleSlug)),
+				Added:          series.Added,
+				Tags:           tags.get(series.Tags),
+				QualityProfile: qualityProfiles[series.QualityProfileID],
+				RootFolder:     series.RootFolderPath,
+				Monitored:      series.Monitored,
+				Ended:          series.Ended || series.Status == "ended",
 			},
 			Files: parts,
 		}
@@ -78,8 +117,61 @@ func (r *SonarrRetriever) GetMedia() ([]domain.MediaEntry, error) {
 	return mediaList, nil
 }
 
-func (r *SonarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring bool) error {
-	episodeFiles, err := r.getEpisodeFiles(fileIds)
+type sonarrSeriesFiles struct {
+	episodeFiles []*sonarr.EpisodeFile
+	episodes     []*sonarr.Episode
+}
+
+func (r *SonarrRetriever) getTags(ctx context.Context) ([]*starr.Tag, error) {
+	return r.client.GetTagsContext(ctx)
+}
+
+func (r *SonarrRetriever) getQualityProfileNames(ctx context.Context) (map[int64]string, error) {
+	profiles, err := r.client.GetQualityProfilesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	qualityProfiles := make(map[int64]string, len(profiles))
+	for _, profile := range profiles {
+		qualityProfiles[profile.ID] = profile.Name
+	}
+	return qualityProfiles, nil
+}
+
+// getSeriesFiles returns the episode files and episodes of every series in seriesList at the same index. Sonarr only
+// supports retrieving them for a single series per request, so up to parallelism series are retrieved concurrently. The
+// episodes of a series are empty if they cannot be retrieved.
+func (r *SonarrRetriever) getSeriesFiles(ctx context.Context, seriesList []*sonarr.Series) ([]sonarrSeriesFiles, error) {
+	return util.MapConcurrently(ctx, seriesList, r.parallelism, func(ctx context.Context, series *sonarr.Series) (sonarrSeriesFiles, error) {
+		seriesEpisodeFiles, err := r.client.GetSeriesEpisodeFilesContext(ctx, series.ID)
+		if err != nil {
+			return sonarrSeriesFiles{}, fmt.Errorf("could not get series episode files: %w", err)
+		}
+		// the episodes only add details to the files, so the series is still listed without them
+		episodes, err := r.client.GetSeriesEpisodesContext(ctx, &sonarr.GetEpisode{SeriesID: series.ID})
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Could not get sonarr episodes. Listing episode files without details.", "instance", r.name, "seriesId", series.ID, "error", err)
+		}
+		return sonarrSeriesFiles{seriesEpisodeFiles, episodes}, nil
+	})
+}
+
+// getHistoryImports returns a page of the imports recorded in the sonarr history.
+func (r *SonarrRetriever) getHistoryImports(ctx context.Context, req *starr.PageReq) ([]historyImport, int, error) {
+	req.Filter = sonarr.FilterDownloadFolderImported
+	history, err := r.client.GetHistoryPageContext(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports := make([]historyImport, 0, len(history.Records))
+	for _, record := range history.Records {
+		imports = append(imports, historyImport{record.DownloadID, record.Data.FileID, record.Data.ImportedPath})
+	}
+	return imports, history.TotalRecords, nil
+}
+
+func (r *SonarrRetriever) DeleteMediaFiles(ctx context.Context, fileIds []int64, stopParentMonitoring bool) error {
+	episodeFiles, err := r.getEpisodeFiles(ctx, fileIds)
 	if err != nil {
 		return fmt.Errorf("could not get sonarr episode files (file ids: %+v): %w", fileIds, err)
 	}
@@ -95,13 +187,13 @@ func (r *SonarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring
 			seriesSeasonMap[episodeFile.SeriesID] = seasonList
 		}
 	}
-	if err = r.deleteEpisodeFiles(fileIds); err != nil {
+	if err = r.deleteEpisodeFiles(ctx, fileIds); err != nil {
 		return err
 	}
 	monitoringUpdateErrors := make([]error, 0)
 	if stopParentMonitoring {
 		for seriesId, seasonList := range seriesSeasonMap {
-			if err = r.stopMonitoringSeasons(seriesId, seasonList); err != nil {
+			if err = r.stopMonitoringSeasons(ctx, seriesId, seasonList); err != nil {
 				monitoringUpdateErrors = append(monitoringUpdateErrors, err)
 			}
 		}
@@ -112,14 +204,14 @@ func (r *SonarrRetriever) DeleteMediaFiles(fileIds []int64, stopParentMonitoring
 	return nil
 }
 
-func (r *SonarrRetriever) getEpisodeFiles(fileIds []int64) ([]*sonarr.EpisodeFile, error) {
+func (r *SonarrRetriever) getEpisodeFiles(ctx context.Context, fileIds []int64) ([]*sonarr.EpisodeFile, error) {
 	req := starr.Request{URI: sonarrEpisodeFileEndpoint, Query: make(url.Values)}
 	for _, efID := range fileIds {
 		req.Query.Add("episodeFileIds", starr.Str(efID))
 	}
 
 	var output []*sonarr.EpisodeFile
-	if err := r.client.GetInto(context.Background(), req, &output); err != nil {
+	if err := r.client.GetInto(ctx, req, &output); err != nil {
 		return nil, fmt.Errorf("api.Get(%s): %w", &req, err)
 	}
 
@@ -130,7 +222,11 @@ func (r *SonarrRetriever) SupportedMediaType() domain.MediaType {
 	return domain.MediaTypeSeries
 }
 
-func (r *SonarrRetriever) deleteEpisodeFiles(fileIds []int64) error {
+func (r *SonarrRetriever) Name() string {
+	return r.name
+}
+
+func (r *SonarrRetriever) deleteEpisodeFiles(ctx context.Context, fileIds []int64) error {
 	payload := struct {
 		EpisodeFileIds []int64 `json:"episodeFileIds"`
 	}{
@@ -145,14 +241,14 @@ func (r *SonarrRetriever) deleteEpisodeFiles(fileIds []int64) error {
 		slog.Info("[DRY RUN] Skipping delete sonarr episode files.", "fileIds", fileIds)
 		return nil
 	}
-	if err = r.client.DeleteAny(context.Background(), req); err != nil {
+	if err = r.client.DeleteAny(ctx, req); err != nil {
 		return fmt.Errorf("could not bulk delete episode files from sonarr: %w",
 			fmt.Errorf("api.Delete(%s): %w", &req, err))
 	}
 	return nil
 }
 
-func (r *SonarrRetriever) stopMonitoringSeasons(seriesId int64, seasonNumbers []int) error {
+func (r *SonarrRetriever) stopMonitoringSeasons(ctx context.Context, seriesId int64, seasonNumbers []int) error {
 	seasons := make([]*sonarr.Season, len(seasonNumbers))
 	for _, season := range seasonNumbers {
 		seasons = append(seasons, &sonarr.Season{
@@ -164,7 +260,7 @@ func (r *SonarrRetriever) stopMonitoringSeasons(seriesId int64, seasonNumbers []
 		slog.Info("[DRY RUN] Skipping sonarr stop monitoring season.", "seriesId", seriesId, "seasons", seasonNumbers)
 		return nil
 	}
-	_, err := r.client.UpdateSeries(&sonarr.AddSeriesInput{
+	_, err := r.client.UpdateSeriesContext(ctx, &sonarr.AddSeriesInput{
 		ID:      seriesId,
 		Seasons: seasons,
 	}, false)
@@ -0,0 +1,284 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSonarrServer serves the api endpoints used to retrieve media for a fixed number of generated series.
+type fakeSonarrServer struct {
+	seriesCount int
+	filesCount  int
+	// latency delays every episode file response to simulate a remote sonarr instance
+	latency time.Duration
+	// failingSeriesId is the id of the series whose episode files cannot be retrieved
+	failingSeriesId int64
+	// failingEpisodesSeriesId is the id of the series whose episodes cannot be retrieved
+	failingEpisodesSeriesId int64
+	// historyRecords is the number of import records in the history
+	historyRecords int
+	// historyRecordsMissing is the number of records which are reported in the total but never returned
+	historyRecordsMissing int
+
+	requests            atomic.Int64
+	episodeFileRequests atomic.Int64
+	historyRequests     atomic.Int64
+	concurrentRequests  atomic.Int64
+	maxConcurrent       atomic.Int64
+}
+
+func (server *fakeSonarrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.requests.Add(1)
+	var result any
+	switch r.URL.Path {
+	case "/api/v3/system/status":
+		result = map[string]any{"appName": "Sonarr"}
+	case "/api/v3/series":
+		series := make([]map[string]any, 0, server.seriesCount)
+		for i := 1; i <= server.seriesCount; i++ {
+			series = append(series, map[string]any{
+				"id":               i,
+				"title":            fmt.Sprintf("Series %d", i),
+				"titleSlug":        fmt.Sprintf("series-%d", i),
+				"added":            "2026-01-01T00:00:00Z",
+				"statistics":       map[string]any{"sizeOnDisk": i % 10},
+				"tags":             []int{1, 3},
+				"qualityProfileId": 2,
+				"rootFolderPath":   "/media",
+				"monitored":        true,
+				"ended":            i%2 == 0,
+			})
+		}
+		result = series
+	case "/api/v3/tag":
+		result = []map[string]any{{"id": 1, "label": "keep"}, {"id": 2, "label": "kids"}}
+	case "/api/v3/qualityProfile":
+		result = []map[string]any{{"id": 2, "name": "HD-1080p"}}
+	case "/api/v3/episodeFile":
+		seriesId, _ := strconv.ParseInt(r.URL.Query().Get("seriesId"), 10, 64)
+		result = server.getEpisodeFiles(seriesId)
+		if seriesId == server.failingSeriesId {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+	case "/api/v3/episode":
+		seriesId, _ := strconv.ParseInt(r.URL.Query().Get("seriesId"), 10, 64)
+		if seriesId == server.failingEpisodesSeriesId {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		result = server.getEpisodes(seriesId)
+	case "/api/v3/history":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		result = server.getHistoryPage(page, pageSize)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (server *fakeSonarrServer) getEpisodeFiles(seriesId int64) []map[string]any {
+	server.episodeFileRequests.Add(1)
+	concurrent := server.concurrentRequests.Add(1)
+	defer server.concurrentRequests.Add(-1)
+	for {
+		maxConcurrent := server.maxConcurrent.Load()
+		if concurrent <= maxConcurrent || server.maxConcurrent.CompareAndSwap(maxConcurrent, concurrent) {
+			break
+		}
+	}
+	time.Sleep(server.latency)
+	episodeFiles := make([]map[string]any, 0, server.filesCount)
+	for i := 1; i <= server.filesCount; i++ {
+		episodeFiles = append(episodeFiles, map[string]any{
+			"id":           seriesId*1000 + int64(i),
+			"seriesId":     seriesId,
+			"seasonNumber": 1,
+			"relativePath": fmt.Sprintf("Season 1/episode %d.mkv", i),
+			"path":         fmt.Sprintf("/media/series-%d/Season 1/episode %d.mkv", seriesId, i),
+			"size":         1000,
+			"releaseGroup": "GROUP",
+			"quality":      map[string]any{"quality": map[string]any{"name": "WEBDL-1080p", "resolution": 1080}},
+		})
+	}
+	return episodeFiles
+}
+
+func (server *fakeSonarrServer) getHistoryPage(page int, pageSize int) map[string]any {
+	server.historyRequests.Add(1)
+	records := make([]map[string]any, 0, pageSize)
+	for i := (page-1)*pageSize + 1; i <= min(page*pageSize, server.historyRecords); i++ {
+		records = append(records, map[string]any{
+			"id":         i,
+			"eventType":  "downloadFolderImported",
+			"downloadId": fmt.Sprintf("download-%d", i),
+			"data":       map[string]any{"fileId": strconv.Itoa(i), "importedPath": fmt.Sprintf("/media/file-%d.mkv", i)},
+		})
+	}
+	return map[string]any{
+		"page":         page,
+		"pageSize":     pageSize,
+		"totalRecords": server.historyRecords + server.historyRecordsMissing,
+		"records":      records,
+	}
+}
+
+// getEpisodes returns an episode per file plus an episode without file.
+func (server *fakeSonarrServer) getEpisodes(seriesId int64) []map[string]any {
+	episodes := make([]map[string]any, 0, server.filesCount+1)
+	for i := 1; i <= server.filesCount+1; i++ {
+		episodeFileId := seriesId*1000 + int64(i)
+		if i > server.filesCount {
+			episodeFileId = 0
+		}
+		episodes = append(episodes, map[string]any{
+			"id":            seriesId*1000 + int64(i),
+			"seriesId":      seriesId,
+			"seasonNumber":  1,
+			"episodeNumber": i,
+			"episodeFileId": episodeFileId,
+			"title":         fmt.Sprintf("Episode %d", i),
+			"airDateUtc":    fmt.Sprintf("2026-01-%02dT20:00:00Z", i),
+		})
+	}
+	return episodes
+}
+
+func newFakeSonarrRetriever(tb testing.TB, server *fakeSonarrServer, parallelism int) *SonarrRetriever {
+	tb.Helper()
+	httpServer := httptest.NewServer(server)
+	tb.Cleanup(httpServer.Close)
+	retriever, err := NewSonarrRetriever("sonarr", httpServer.URL+"/", "secret", time.Minute, parallelism, false)
+	if err != nil {
+		tb.Fatalf("NewSonarrRetriever() error = %v", err)
+	}
+	return retriever
+}
+
+func TestSonarrRetriever_GetMedia(t *testing.T) {
+	server := &fakeSonarrServer{seriesCount: 50, filesCount: 2, latency: time.Millisecond}
+	retriever := newFakeSonarrRetriever(t, server, 4)
+
+	mediaList, err := retriever.GetMedia(context.Background())
+	if err != nil {
+		t.Fatalf("GetMedia() error = %v", err)
+	}
+	// every tenth series has no files on disk and is skipped
+	assert.Len(t, mediaList, 45)
+	assert.Equal(t, int64(45), server.episodeFileRequests.Load())
+	assert.LessOrEqual(t, server.maxConcurrent.Load(), int64(4))
+	for _, media := range mediaList {
+		assert.NotZero(t, media.Id%10)
+		assert.Equal(t, fmt.Sprintf("Series %d", media.Id), media.Title)
+		// unknown tag ids are skipped
+		assert.Equal(t, []string{"keep"}, media.Tags)
+		assert.Equal(t, "HD-1080p", media.QualityProfile)
+		assert.Equal(t, "/media", media.RootFolder)
+		assert.True(t, media.Monitored)
+		assert.Equal(t, media.Id%2 == 0, media.Ended)
+		assert.Equal(t, []domain.MediaFile{{
+			Id:               media.Id*1000 + 1,
+			Season:           1,
+			OriginalFilePath: "episode 1.mkv",
+			Path:             fmt.Sprintf("/media/series-%d/Season 1/episode 1.mkv", media.Id),
+			Size:             1000,
+			EpisodeNumbers:   []int{1},
+			EpisodeTitle:     "Episode 1",
+			AirDate:          time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC),
+			Quality:          "WEBDL-1080p",
+			Resolution:       1080,
+			ReleaseGroup:     "GROUP",
+		}, {
+			Id:               media.Id*1000 + 2,
+			Season:           1,
+			OriginalFilePath: "episode 2.mkv",
+			Path:             fmt.Sprintf("/media/series-%d/Season 1/episode 2.mkv", media.Id),
+			Size:             1000,
+			EpisodeNumbers:   []int{2},
+			EpisodeTitle:     "Episode 2",
+			AirDate:          time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC),
+			Quality:          "WEBDL-1080p",
+			Resolution:       1080,
+			ReleaseGroup:     "GROUP",
+		}}, media.Files)
+	}
+
+	server.failingEpisodesSeriesId = 23
+	mediaList, err = retriever.GetMedia(context.Background())
+	if err != nil {
+		t.Fatalf("GetMedia() error = %v", err)
+	}
+	assert.Len(t, mediaList, 45)
+	for _, media := range mediaList {
+		if media.Id == 23 {
+			assert.Empty(t, media.Files[0].EpisodeNumbers)
+			assert.Empty(t, media.Files[0].EpisodeTitle)
+			assert.Equal(t, "WEBDL-1080p", media.Files[0].Quality)
+		}
+	}
+
+	server.failingSeriesId = 23
+	_, err = retriever.GetMedia(context.Background())
+	assert.ErrorContains(t, err, "could not get series episode files")
+}
+
+func Test_getDownloadIds(t *testing.T) {
+	tests := []struct {
+		name                  string
+		historyRecords        int
+		historyRecordsMissing int
+		wantRequests          int64
+	}{
+		{"empty history", 0, 0, 1},
+		{"single partial page", 10, 0, 1},
+		{"exactly one page", historyPageSize, 0, 1},
+		{"last page partial", 2*historyPageSize + 1, 0, 3},
+		{"stops on empty page", historyPageSize, historyPageSize * 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeSonarrServer{historyRecords: tt.historyRecords, historyRecordsMissing: tt.historyRecordsMissing}
+			retriever := newFakeSonarrRetriever(t, server, 1)
+
+			downloadIds, err := getDownloadIds(context.Background(), retriever.getHistoryImports)
+			if err != nil {
+				t.Fatalf("getDownloadIds() error = %v", err)
+			}
+			assert.Equal(t, tt.wantRequests, server.historyRequests.Load())
+			assert.Len(t, downloadIds.byFileId, tt.historyRecords)
+			if tt.historyRecords > 0 {
+				assert.Equal(t, fmt.Sprintf("download-%d", tt.historyRecords),
+					downloadIds.get(int64(tt.historyRecords), ""))
+			}
+		})
+	}
+}
+
+func BenchmarkSonarrRetriever_GetMedia(b *testing.B) {
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			server := &fakeSonarrServer{seriesCount: 900, filesCount: 20, latency: time.Millisecond}
+			retriever := newFakeSonarrRetriever(b, server, parallelism)
+			server.requests.Store(0)
+			for b.Loop() {
+				if _, err := retriever.GetMedia(context.Background()); err != nil {
+					b.Fatalf("GetMedia() error = %v", err)
+				}
+			}
+			b.ReportMetric(float64(server.requests.Load())/float64(b.N), "requests/op")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package quota
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -17,22 +18,22 @@ type UltraApiQuotaService struct {
 	lastRetrievedTs time.Time
 }
 
-func NewUltraApiQuotaService(endpoint string, authToken []byte) *UltraApiQuotaService {
-	instance := ultraapi.New(endpoint, authToken)
+func NewUltraApiQuotaService(endpoint string, authToken []byte, timeout time.Duration) *UltraApiQuotaService {
+	instance := ultraapi.New(endpoint, authToken, timeout)
 	return &UltraApiQuotaService{
 		Mutex:    &sync.Mutex{},
 		ultraApi: instance,
 	}
 }
 
-func (service *UltraApiQuotaService) GetDiskQuota() (webserver.DiskQuota, error) {
+func (service *UltraApiQuotaService) GetDiskQuota(ctx context.Context) (webserver.DiskQuota, error) {
 	service.Lock()
 	defer service.Unlock()
 	if !service.lastRetrievedTs.IsZero() && time.Since(service.lastRetrievedTs) < time.Second*30 {
 		return service.lastDiskQuota, nil
 	}
 	slog.Debug("Retrieving fresh disk quota from Ultra API...")
-	ultraDiskQuota, err := service.ultraApi.GetDiskQuota()
+	ultraDiskQuota, err := service.ultraApi.GetDiskQuota(ctx)
 	if err != nil {
 		return webserver.DiskQuota{}, fmt.Errorf("error getting disk quota from ultra api: %v", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
 	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
@@ -14,39 +16,64 @@ var _ inventory.RetentionPolicy = (*Service)(nil)
 
 var now = time.Now
 
+// DefaultUnregisteredPatterns match the tracker messages of common trackers for deleted or trumped torrents.
+var DefaultUnregisteredPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)unregistered`),
+	regexp.MustCompile(`(?i)not registered`),
+	regexp.MustCompile(`(?i)torrent not found`),
+	regexp.MustCompile(`(?i)infohash not found`),
+	regexp.MustCompile(`(?i)trumped`),
+	regexp.MustCompile(`(?i)torrent has been deleted`),
+}
+
 type Service struct {
 	trackerResolver TrackerResolver
+	// unregisteredPatterns are matched against the tracker status of torrents to detect deleted or trumped torrents
+	unregisteredPatterns []*regexp.Regexp
+	// deletableStates are the torrent states which are safe to delete regardless of the tracker requirements, e.g. paused
+	// torrents which will never meet them anyway
+	deletableStates []domain.TorrentState
+	// keepTags are the *arr tags protecting media from deletion regardless of its torrents
+	keepTags []string
 }
 
-func NewService(trackerResolver TrackerResolver) *Service {
-	return &Service{trackerResolver: trackerResolver}
+func NewService(trackerResolver TrackerResolver, unregisteredPatterns []*regexp.Regexp, deletableStates []domain.TorrentState, keepTags []string) *Service {
+	return &Service{trackerResolver: trackerResolver, unregisteredPatterns: unregisteredPatterns, deletableStates: deletableStates, keepTags: keepTags}
 }
 
 func (s Service) Evaluate(media inventory.LinkedMedia) (inventory.EvaluationReport, error) {
 	globalDecision := domain.DecisionSafeToDelete
+	kept := s.isKept(media.MediaMetadata)
 	files := make(map[int64]inventory.EvaluationReportPart)
-	for _, linkedMediaFile := range media.Files {
+	for fileIndex, linkedMediaFile := range media.Files {
 		var tracker *domain.Tracker
-		safeToDelete := true
-		torrentEntry := linkedMediaFile.TorrentEntry
-		if torrentEntry != nil {
-			safeToDelete = false
-			var err error
-			tracker, err = s.trackerResolver.Resolve(torrentEntry.Trackers)
+		decision := domain.DecisionSafeToDelete
+		// every torrent seeding the file has to be safe to delete. The reported tracker is the one of the first torrent
+		// blocking the deletion or of the first torrent if none does.
+		for i, torrentEntry := range linkedMediaFile.TorrentEntries {
+			torrentTracker, err := s.resolveTracker(torrentEntry)
 			if errors.Is(err, ErrTrackerNotFound) {
-				slog.Warn("tracker not found for linked media file", "linkedMediaFile", linkedMediaFile)
+				slog.Warn("tracker not found for linked media file", "linkedMediaFile", linkedMediaFile, "torrentEntry", torrentEntry)
 			} else if err != nil {
 				return inventory.EvaluationReport{}, fmt.Errorf("could not resolve tracker for linked media file (%+v): %w", linkedMediaFile, err)
 			}
-
-			if tracker != nil {
-				safeToDelete = isTorrentEntrySafeToDelete(torrentEntry, tracker)
+			torrentDecision := s.evaluateTorrentEntry(torrentEntry, torrentTracker)
+			if i == 0 || (decision != domain.DecisionPending && torrentDecision == domain.DecisionPending) {
+				tracker = torrentTracker
+			}
+			if i == 0 {
+				decision = torrentDecision
+			} else {
+				decision = combineDecisions(decision, torrentDecision)
 			}
 		}
-		decision := domain.DecisionSafeToDelete
-		if !safeToDelete {
+		if kept {
 			decision = domain.DecisionPending
-			globalDecision = domain.DecisionPending
+		}
+		if fileIndex == 0 {
+			globalDecision = decision
+		} else {
+			globalDecision = combineDecisions(globalDecision, decision)
 		}
 		files[linkedMediaFile.Id] = inventory.EvaluationReportPart{
 			Decision: decision,
@@ -54,7 +81,8 @@ func (s Service) Evaluate(media inventory.LinkedMedia) (inventory.EvaluationRepo
 		}
 	}
 	var seasons map[int]inventory.EvaluationReportPart = nil
-	if media.Type == domain.MediaTypeSeries {
+	// files of all media types except movies are grouped into seasons, albums or books
+	if media.Type != domain.MediaTypeMovie {
 		seasons = make(map[int]inventory.EvaluationReportPart)
 		for _, linkedMediaFile := range media.Files {
 			if linkedMediaFile.Season == -1 {
@@ -67,8 +95,8 @@ func (s Service) Evaluate(media inventory.LinkedMedia) (inventory.EvaluationRepo
 					Decision: fileReport.Decision,
 				}
 			} else {
-				if existingReport.Decision == domain.DecisionSafeToDelete && fileReport.Decision != domain.DecisionSafeToDelete {
-					existingReport.Decision = fileReport.Decision
+				if combinedDecision := combineDecisions(existingReport.Decision, fileReport.Decision); combinedDecision != existingReport.Decision {
+					existingReport.Decision = combinedDecision
 					seasons[linkedMediaFile.Season] = existingReport
 				}
 				if existingReport.Tracker != fileReport.Tracker {
@@ -86,19 +114,87 @@ func (s Service) Evaluate(media inventory.LinkedMedia) (inventory.EvaluationRepo
 	}, nil
 }
 
+// isKept reports whether the media has one of the keep tags.
+func (s Service) isKept(media domain.MediaMetadata) bool {
+	return slices.ContainsFunc(media.Tags, func(tag string) bool {
+		return slices.Contains(s.keepTags, tag)
+	})
+}
+
 func (s Service) EvaluateTorrentEntry(torrent *domain.TorrentEntry) (domain.Decision, *domain.Tracker, error) {
-	tracker, err := s.trackerResolver.Resolve(torrent.Trackers)
+	tracker, err := s.resolveTracker(torrent)
 	if errors.Is(err, ErrTrackerNotFound) {
-		return domain.DecisionPending, nil, nil
+		tracker = nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("could not resolve tracker for torrent entry (%s): %w", torrent, err)
+	}
+	return s.evaluateTorrentEntry(torrent, tracker), tracker, nil
+}
+
+// resolveTracker returns the rule of the torrent. Label rules take precedence over tracker rules, so labels can
+// protect torrents or override the thresholds of their tracker.
+func (s Service) resolveTracker(torrentEntry *domain.TorrentEntry) (*domain.Tracker, error) {
+	if len(torrentEntry.Labels) > 0 {
+		tracker, err := s.trackerResolver.ResolveLabels(torrentEntry.Labels)
+		if err == nil {
+			return tracker, nil
+		} else if !errors.Is(err, ErrTrackerNotFound) {
+			return nil, err
+		}
+	}
+	return s.trackerResolver.Resolve(torrentEntry.Trackers)
+}
+
+// evaluateTorrentEntry decides on a single torrent. Incomplete and kept torrents are always pending. Torrents without a
+// known tracker stay pending unless the tracker reported them as unregistered or their state is deletable.
+func (s Service) evaluateTorrentEntry(torrentEntry *domain.TorrentEntry, tracker *domain.Tracker) domain.Decision {
+	if isTorrentEntryIncomplete(torrentEntry) {
+		return domain.DecisionPending
+	}
+	if tracker != nil && tracker.Keep {
+		return domain.DecisionPending
+	}
+	if s.isUnregistered(torrentEntry) {
+		return domain.DecisionUnregistered
+	}
+	if slices.Contains(s.deletableStates, torrentEntry.State) {
+		return domain.DecisionSafeToDelete
+	}
+	if tracker != nil && isTorrentEntrySafeToDelete(torrentEntry, tracker) {
+		return domain.DecisionSafeToDelete
+	}
+	return domain.DecisionPending
+}
+
+func (s Service) isUnregistered(torrentEntry *domain.TorrentEntry) bool {
+	if torrentEntry.TrackerStatus == "" {
+		return false
+	}
+	return slices.ContainsFunc(s.unregisteredPatterns, func(pattern *regexp.Regexp) bool {
+		return pattern.MatchString(torrentEntry.TrackerStatus)
+	})
+}
+
+// isTorrentEntryIncomplete reports whether the torrent is still being downloaded or checked. Its data might still be
+// imported, so it must not be deleted.
+func isTorrentEntryIncomplete(torrentEntry *domain.TorrentEntry) bool {
+	switch torrentEntry.State {
+	case domain.TorrentStateDownloading, domain.TorrentStateChecking:
+		return true
 	}
-	if err != nil {
-		slog.Warn("tracker not found for torrent entry", "torrentEntry", torrent)
-		return domain.DecisionPending, nil, nil
+	return torrentEntry.Added.IsZero()
+}
+
+// combineDecisions returns the decision for a group of entries. A single pending entry blocks the whole group while the
+// group is only considered unregistered if all of its entries are.
+func combineDecisions(a, b domain.Decision) domain.Decision {
+	if a == domain.DecisionPending || b == domain.DecisionPending {
+		return domain.DecisionPending
 	}
-	if isTorrentEntrySafeToDelete(torrent, tracker) {
-		return domain.DecisionSafeToDelete, tracker, nil
+	if a == domain.DecisionUnregistered && b == domain.DecisionUnregistered {
+		return domain.DecisionUnregistered
 	}
-	return domain.DecisionPending, tracker, nil
+	return domain.DecisionSafeToDelete
 }
 
 func isTorrentEntrySafeToDelete(torrentEntry *domain.TorrentEntry, tracker *domain.Tracker) bool {
@@ -108,5 +204,9 @@ func isTorrentEntrySafeToDelete(torrentEntry *domain.TorrentEntry, tracker *doma
 	if torrentEntry.Added.Add(tracker.MinAge).After(now()) {
 		return false
 	}
+	// an unknown seeding time (-1) never satisfies the requirement
+	if tracker.MinSeedTime > 0 && torrentEntry.SeedingTime < tracker.MinSeedTime {
+		return false
+	}
 	return true
 }
@@ -1,6 +1,7 @@
 package retentionpolicy
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -18,6 +19,25 @@ func (m mockTrackerResolver) Resolve(_ []string) (*domain.Tracker, error) {
 	return m.tracker, nil
 }
 
+func (m mockTrackerResolver) ResolveLabels(_ []string) (*domain.Tracker, error) {
+	return nil, ErrTrackerNotFound
+}
+
+// mockLabelTrackerResolver resolves the rules of labels by their name.
+type mockLabelTrackerResolver struct {
+	mockTrackerResolver
+	labelTrackers map[string]*domain.Tracker
+}
+
+func (m mockLabelTrackerResolver) ResolveLabels(labels []string) (*domain.Tracker, error) {
+	for _, label := range labels {
+		if tracker, ok := m.labelTrackers[label]; ok {
+			return tracker, nil
+		}
+	}
+	return nil, ErrTrackerNotFound
+}
+
 func TestService_Evaluate(t *testing.T) {
 	mediaMetadata := domain.MediaMetadata{
 		Id:    1337,
@@ -40,13 +60,13 @@ func TestService_Evaluate(t *testing.T) {
 			Id:     13371,
 			Season: -1,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			Added: util.MustParseDate("2025-12-16 13:14:15"),
-		},
+		}},
 	}
 	linkedMediaFileNoTorrent := linkedMediaFile
-	linkedMediaFileNoTorrent.TorrentEntry = nil
+	linkedMediaFileNoTorrent.TorrentEntries = nil
 
 	// season 1 is safe to delete (1) and pending (2)
 	linkedMediaFileSeason1E1 := inventory.LinkedMediaFile{
@@ -54,22 +74,22 @@ func TestService_Evaluate(t *testing.T) {
 			Id:     1337_1_1,
 			Season: 1,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			// use old Added to satisfy high age tracker
 			Added: util.MustParseDate("2023-12-16 13:14:15"),
-		},
+		}},
 	}
 	linkedMediaFileSeason1E2 := inventory.LinkedMediaFile{
 		MediaFile: domain.MediaFile{
 			Id:     1337_1_2,
 			Season: 1,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			// use recent date to not satisfy high age tracker
 			Added: util.MustParseDate("2025-12-16 13:14:15"),
-		},
+		}},
 	}
 	// season 2 is safe to delete
 	linkedMediaFileSeason2E1 := inventory.LinkedMediaFile{
@@ -77,11 +97,11 @@ func TestService_Evaluate(t *testing.T) {
 			Id:     1337_2_1,
 			Season: 2,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			// use old Added to satisfy high age tracker
 			Added: util.MustParseDate("2023-12-16 13:14:15"),
-		},
+		}},
 	}
 	// season 3 is pending
 	linkedMediaFileSeason3E1 := inventory.LinkedMediaFile{
@@ -89,10 +109,10 @@ func TestService_Evaluate(t *testing.T) {
 			Id:     1337_3_1,
 			Season: 3,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			Added: util.MustParseDate("2025-12-16 13:14:15"),
-		},
+		}},
 	}
 	// file without series
 	linkedMediaFileSeasonNoSeason := inventory.LinkedMediaFile{
@@ -100,10 +120,40 @@ func TestService_Evaluate(t *testing.T) {
 			Id:     1337_1,
 			Season: -1,
 		},
-		TorrentEntry: &domain.TorrentEntry{
+		TorrentEntries: []*domain.TorrentEntry{{
 			Ratio: 0,
 			// use old Added to satisfy high age tracker
 			Added: util.MustParseDate("2023-12-16 13:14:15"),
+		}},
+	}
+
+	// the first torrent is old enough for the high age tracker while the cross-seeded one is not
+	linkedMediaFileCrossSeeded := linkedMediaFile
+	linkedMediaFileCrossSeeded.TorrentEntries = []*domain.TorrentEntry{
+		{
+			Ratio: 0,
+			Added: util.MustParseDate("2023-12-16 13:14:15"),
+		},
+		{
+			Ratio: 0,
+			Added: util.MustParseDate("2025-12-16 13:14:15"),
+		},
+	}
+
+	linkedMediaFileUnregistered := linkedMediaFile
+	linkedMediaFileUnregistered.TorrentEntries = []*domain.TorrentEntry{{
+		Ratio:         0,
+		Added:         util.MustParseDate("2025-12-16 13:14:15"),
+		TrackerStatus: "Error: Unregistered torrent",
+	}}
+
+	// the unregistered torrent does not unblock the deletion while the cross-seeded one is still pending
+	linkedMediaFileUnregisteredCrossSeeded := linkedMediaFile
+	linkedMediaFileUnregisteredCrossSeeded.TorrentEntries = []*domain.TorrentEntry{
+		linkedMediaFileUnregistered.TorrentEntries[0],
+		{
+			Ratio: 0,
+			Added: util.MustParseDate("2025-12-16 13:14:15"),
 		},
 	}
 
@@ -113,6 +163,23 @@ func TestService_Evaluate(t *testing.T) {
 	trackerHighAge := tracker
 	trackerHighAge.MinAge = time.Hour * 24 * 365
 
+	trackerSeedTime := tracker
+	trackerSeedTime.MinSeedTime = time.Hour * 24 * 7
+
+	// the torrent is old enough but was paused most of the time
+	linkedMediaFileShortSeedingTime := linkedMediaFile
+	linkedMediaFileShortSeedingTime.TorrentEntries = []*domain.TorrentEntry{{
+		Ratio:       0,
+		Added:       util.MustParseDate("2025-12-16 13:14:15"),
+		SeedingTime: time.Hour * 24,
+	}}
+	linkedMediaFileLongSeedingTime := linkedMediaFile
+	linkedMediaFileLongSeedingTime.TorrentEntries = []*domain.TorrentEntry{{
+		Ratio:       0,
+		Added:       util.MustParseDate("2025-12-16 13:14:15"),
+		SeedingTime: time.Hour * 24 * 8,
+	}}
+
 	type fields struct {
 		trackerResolver TrackerResolver
 	}
@@ -171,6 +238,90 @@ func TestService_Evaluate(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"allowed delete eval - unregistered torrent",
+			fields{mockTrackerResolver{&trackerHighRatio}},
+			args{
+				inventory.LinkedMedia{
+					MediaMetadata: mediaMetadata,
+					Files:         []inventory.LinkedMediaFile{linkedMediaFileUnregistered},
+				},
+			},
+			inventory.EvaluationReport{
+				Result:  inventory.EvaluationReportPart{Decision: domain.DecisionUnregistered},
+				Seasons: nil,
+				Files: map[int64]inventory.EvaluationReportPart{
+					13371: {
+						Decision: domain.DecisionUnregistered,
+						Tracker:  &trackerHighRatio,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"disallowed delete eval - unregistered torrent with pending cross-seed",
+			fields{mockTrackerResolver{&trackerHighRatio}},
+			args{
+				inventory.LinkedMedia{
+					MediaMetadata: mediaMetadata,
+					Files:         []inventory.LinkedMediaFile{linkedMediaFileUnregisteredCrossSeeded},
+				},
+			},
+			inventory.EvaluationReport{
+				Result:  inventory.EvaluationReportPart{Decision: domain.DecisionPending},
+				Seasons: nil,
+				Files: map[int64]inventory.EvaluationReportPart{
+					13371: {
+						Decision: domain.DecisionPending,
+						Tracker:  &trackerHighRatio,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"allowed delete eval - seeding time fulfilled",
+			fields{mockTrackerResolver{&trackerSeedTime}},
+			args{
+				inventory.LinkedMedia{
+					MediaMetadata: mediaMetadata,
+					Files:         []inventory.LinkedMediaFile{linkedMediaFileLongSeedingTime},
+				},
+			},
+			inventory.EvaluationReport{
+				Result:  inventory.EvaluationReportPart{Decision: domain.DecisionSafeToDelete},
+				Seasons: nil,
+				Files: map[int64]inventory.EvaluationReportPart{
+					13371: {
+						Decision: domain.DecisionSafeToDelete,
+						Tracker:  &trackerSeedTime,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"disallowed delete eval - seeding time not fulfilled",
+			fields{mockTrackerResolver{&trackerSeedTime}},
+			args{
+				inventory.LinkedMedia{
+					MediaMetadata: mediaMetadata,
+					Files:         []inventory.LinkedMediaFile{linkedMediaFileShortSeedingTime},
+				},
+			},
+			inventory.EvaluationReport{
+				Result:  inventory.EvaluationReportPart{Decision: domain.DecisionPending},
+				Seasons: nil,
+				Files: map[int64]inventory.EvaluationReportPart{
+					13371: {
+						Decision: domain.DecisionPending,
+						Tracker:  &trackerSeedTime,
+					},
+				},
+			},
+			false,
+		},
 		{
 			"disallowed delete eval - ratio not fulfilled",
 			fields{mockTrackerResolver{&trackerHighRatio}},
@@ -213,6 +364,27 @@ func TestService_Evaluate(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"disallowed delete eval - cross-seeded torrent not fulfilled",
+			fields{mockTrackerResolver{&trackerHighAge}},
+			args{
+				inventory.LinkedMedia{
+					MediaMetadata: mediaMetadata,
+					Files:         []inventory.LinkedMediaFile{linkedMediaFileCrossSeeded},
+				},
+			},
+			inventory.EvaluationReport{
+				Result:  inventory.EvaluationReportPart{Decision: domain.DecisionPending},
+				Seasons: nil,
+				Files: map[int64]inventory.EvaluationReportPart{
+					13371: {
+						Decision: domain.DecisionPending,
+						Tracker:  &trackerHighAge,
+					},
+				},
+			},
+			false,
+		},
 		{
 			"disallowed delete eval - seasons",
 			fields{mockTrackerResolver{&trackerHighAge}},
@@ -313,7 +485,8 @@ func TestService_Evaluate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Service{
-				trackerResolver: tt.fields.trackerResolver,
+				trackerResolver:      tt.fields.trackerResolver,
+				unregisteredPatterns: DefaultUnregisteredPatterns,
 			}
 			got, err := s.Evaluate(tt.args.media)
 			if (err != nil) != tt.wantErr {
@@ -324,3 +497,167 @@ func TestService_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestService_Evaluate_KeepTags(t *testing.T) {
+	tracker := domain.Tracker{Name: "mockTracker"}
+	media := inventory.LinkedMedia{
+		MediaMetadata: domain.MediaMetadata{Id: 1337, Type: domain.MediaTypeMovie, Title: "Some Movie"},
+		Files: []inventory.LinkedMediaFile{{
+			MediaFile: domain.MediaFile{Id: 13371},
+			TorrentEntries: []*domain.TorrentEntry{{
+				Ratio: 2,
+				State: domain.TorrentStateSeeding,
+				Added: util.MustParseDate("2025-12-16 13:14:15"),
+			}},
+		}},
+	}
+	mediaKept := media
+	mediaKept.Tags = []string{"kids", "keep"}
+	mediaOtherTag := media
+	mediaOtherTag.Tags = []string{"kids"}
+
+	now = func() time.Time {
+		return util.MustParseDate("2026-02-01 13:17:09")
+	}
+	tests := []struct {
+		name  string
+		media inventory.LinkedMedia
+		want  domain.Decision
+	}{
+		{"media without tags", media, domain.DecisionSafeToDelete},
+		{"media with other tag", mediaOtherTag, domain.DecisionSafeToDelete},
+		{"media with keep tag", mediaKept, domain.DecisionPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(mockTrackerResolver{&tracker}, DefaultUnregisteredPatterns, nil, []string{"keep"})
+			got, err := s.Evaluate(tt.media)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Result.Decision)
+			assert.Equal(t, tt.want, got.Files[13371].Decision)
+			assert.Equal(t, &tracker, got.Files[13371].Tracker)
+		})
+	}
+}
+
+func TestService_EvaluateTorrentEntry(t *testing.T) {
+	trackerHighRatio := domain.Tracker{
+		Name:     "mockTracker",
+		MinRatio: 100,
+	}
+
+	torrentEntry := domain.TorrentEntry{
+		Ratio: 0,
+		State: domain.TorrentStateSeeding,
+		Added: util.MustParseDate("2025-12-16 13:14:15"),
+	}
+	torrentEntryPaused := torrentEntry
+	torrentEntryPaused.State = domain.TorrentStatePaused
+	torrentEntryErrored := torrentEntry
+	torrentEntryErrored.State = domain.TorrentStateError
+	torrentEntryDownloading := torrentEntry
+	torrentEntryDownloading.State = domain.TorrentStateDownloading
+	torrentEntryDownloading.Added = time.Time{}
+	torrentEntryDownloadingUnregistered := torrentEntryDownloading
+	torrentEntryDownloadingUnregistered.TrackerStatus = "Error: Unregistered torrent"
+
+	now = func() time.Time {
+		return util.MustParseDate("2026-02-01 13:17:09")
+	}
+	tests := []struct {
+		name            string
+		deletableStates []domain.TorrentState
+		torrentEntry    *domain.TorrentEntry
+		want            domain.Decision
+	}{
+		{"seeding torrent with ratio not fulfilled", nil, &torrentEntry, domain.DecisionPending},
+		{"paused torrent without deletable states", nil, &torrentEntryPaused, domain.DecisionPending},
+		{"paused torrent with deletable state", []domain.TorrentState{domain.TorrentStatePaused}, &torrentEntryPaused, domain.DecisionSafeToDelete},
+		{"errored torrent with other deletable state", []domain.TorrentState{domain.TorrentStatePaused}, &torrentEntryErrored, domain.DecisionPending},
+		{"errored torrent with deletable state", []domain.TorrentState{domain.TorrentStateError}, &torrentEntryErrored, domain.DecisionSafeToDelete},
+		{"downloading torrent with deletable state", []domain.TorrentState{domain.TorrentStateDownloading}, &torrentEntryDownloading, domain.DecisionPending},
+		{"downloading unregistered torrent", nil, &torrentEntryDownloadingUnregistered, domain.DecisionPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(mockTrackerResolver{&trackerHighRatio}, DefaultUnregisteredPatterns, tt.deletableStates, nil)
+			got, tracker, err := s.EvaluateTorrentEntry(tt.torrentEntry)
+			assert.NoError(t, err)
+			assert.Equal(t, &trackerHighRatio, tracker)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_EvaluateTorrentEntry_Labels(t *testing.T) {
+	trackerLowRatio := domain.Tracker{Name: "mockTracker", MinRatio: 1}
+	keepLabel := domain.Tracker{Name: "keep", Keep: true}
+	racingLabel := domain.Tracker{Name: "racing", MinRatio: 3}
+
+	torrentEntry := domain.TorrentEntry{
+		Ratio: 2,
+		State: domain.TorrentStateSeeding,
+		Added: util.MustParseDate("2025-12-16 13:14:15"),
+	}
+	torrentEntryKeep := torrentEntry
+	torrentEntryKeep.Labels = []string{"keep"}
+	torrentEntryKeepUnregistered := torrentEntryKeep
+	torrentEntryKeepUnregistered.TrackerStatus = "Error: Unregistered torrent"
+	torrentEntryRacing := torrentEntry
+	torrentEntryRacing.Labels = []string{"movies", "racing"}
+	torrentEntryOther := torrentEntry
+	torrentEntryOther.Labels = []string{"movies"}
+
+	now = func() time.Time {
+		return util.MustParseDate("2026-02-01 13:17:09")
+	}
+	tests := []struct {
+		name         string
+		torrentEntry *domain.TorrentEntry
+		wantTracker  *domain.Tracker
+		want         domain.Decision
+	}{
+		{"torrent without labels", &torrentEntry, &trackerLowRatio, domain.DecisionSafeToDelete},
+		{"torrent with unknown label", &torrentEntryOther, &trackerLowRatio, domain.DecisionSafeToDelete},
+		{"torrent with keep label", &torrentEntryKeep, &keepLabel, domain.DecisionPending},
+		{"unregistered torrent with keep label", &torrentEntryKeepUnregistered, &keepLabel, domain.DecisionPending},
+		{"torrent with racing label", &torrentEntryRacing, &racingLabel, domain.DecisionPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(mockLabelTrackerResolver{
+				mockTrackerResolver{&trackerLowRatio},
+				map[string]*domain.Tracker{"keep": &keepLabel, "racing": &racingLabel},
+			}, DefaultUnregisteredPatterns, []domain.TorrentState{domain.TorrentStatePaused}, nil)
+			got, tracker, err := s.EvaluateTorrentEntry(tt.torrentEntry)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTracker, tracker)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// failingTrackerResolver fails to resolve any tracker.
+type failingTrackerResolver struct {
+	mockTrackerResolver
+	err error
+}
+
+func (m failingTrackerResolver) Resolve(_ []string) (*domain.Tracker, error) {
+	return nil, m.err
+}
+
+func TestService_EvaluateTorrentEntry_ResolverError(t *testing.T) {
+	torrentEntry := &domain.TorrentEntry{Client: "deluge", Id: "torrent", State: domain.TorrentStateSeeding}
+
+	s := NewService(failingTrackerResolver{err: ErrTrackerNotFound}, DefaultUnregisteredPatterns, nil, nil)
+	got, tracker, err := s.EvaluateTorrentEntry(torrentEntry)
+	assert.NoError(t, err)
+	assert.Nil(t, tracker)
+	assert.Equal(t, domain.DecisionPending, got)
+
+	resolverErr := errors.New("resolver failed")
+	s = NewService(failingTrackerResolver{err: resolverErr}, DefaultUnregisteredPatterns, nil, nil)
+	_, _, err = s.EvaluateTorrentEntry(torrentEntry)
+	assert.ErrorIs(t, err, resolverErr)
+}
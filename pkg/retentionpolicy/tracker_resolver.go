@@ -10,4 +10,7 @@ var ErrTrackerNotFound = errors.New("tracker not found")
 
 type TrackerResolver interface {
 	Resolve(trackers []string) (*domain.Tracker, error)
+	// ResolveLabels returns the rule matching one of the labels of a torrent. It returns ErrTrackerNotFound if no rule
+	// matches.
+	ResolveLabels(labels []string) (*domain.Tracker, error)
 }
@@ -0,0 +1,156 @@
+package seedstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+)
+
+var _ inventory.SeedStore = (*Store)(nil)
+
+// recordRetention is the time after which records of torrents that are no longer seen are dropped.
+const recordRetention = 30 * 24 * time.Hour
+
+type Record struct {
+	FirstSeen   time.Time     `json:"first_seen"`
+	LastSeen    time.Time     `json:"last_seen"`
+	SeedingTime time.Duration `json:"seeding_time"`
+	Uploaded    int64         `json:"uploaded"`
+	// Ratio and ClientSeedingTime are the last reported values which are used to calculate the deltas of the next
+	// observation.
+	Ratio             float64       `json:"ratio"`
+	ClientSeedingTime time.Duration `json:"client_seeding_time"`
+}
+
+// Store persists observations of torrents to a json file so that seeding time and uploaded bytes survive restarts as
+// well as torrents being re-checked or moved.
+type Store struct {
+	*sync.Mutex
+	path string
+	// maxObservationGap caps the seeding time accounted between two observations as nothing is known about the torrent
+	// while scrubarr was not running.
+	maxObservationGap time.Duration
+	records           map[string]*Record
+}
+
+func NewStore(path string, maxObservationGap time.Duration) (*Store, error) {
+	store := &Store{
+		Mutex:             &sync.Mutex{},
+		path:              path,
+		maxObservationGap: maxObservationGap,
+		records:           make(map[string]*Record),
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not open seed store: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if err = json.NewDecoder(file).Decode(&store.records); err != nil {
+		return nil, fmt.Errorf("could not decode seed store: %w", err)
+	}
+	return store, nil
+}
+
+func getRecordKey(client, id string) string {
+	return client + "|" + id
+}
+
+func (store *Store) Observe(torrents []*domain.TorrentEntry, at time.Time) (map[[2]string]inventory.SeedObservation, error) {
+	store.Lock()
+	defer store.Unlock()
+	observations := make(map[[2]string]inventory.SeedObservation, len(torrents))
+	for _, torrent := range torrents {
+		record := store.observeTorrent(torrent, at)
+		observations[[2]string{torrent.Client, torrent.Id}] = inventory.SeedObservation{
+			SeedingTime: record.SeedingTime,
+			Uploaded:    record.Uploaded,
+		}
+	}
+	for key, record := range store.records {
+		if at.Sub(record.LastSeen) > recordRetention {
+			delete(store.records, key)
+		}
+	}
+	if err := store.save(); err != nil {
+		return nil, err
+	}
+	return observations, nil
+}
+
+func (store *Store) observeTorrent(torrent *domain.TorrentEntry, at time.Time) *Record {
+	size := int64(0)
+	for _, file := range torrent.Files {
+		size += file.Size
+	}
+	key := getRecordKey(torrent.Client, torrent.Id)
+	record, ok := store.records[key]
+	if !ok {
+		// start with what the client knows as the torrent might have been seeding long before scrubarr saw it
+		record = &Record{
+			FirstSeen: at,
+			LastSeen:  at,
+			Uploaded:  int64(torrent.Ratio * float64(size)),
+		}
+		if torrent.SeedingTime >= 0 {
+			record.SeedingTime = torrent.SeedingTime
+		} else if !torrent.Added.IsZero() && torrent.Added.Before(at) {
+			record.SeedingTime = at.Sub(torrent.Added)
+		}
+		store.records[key] = record
+	} else if at.After(record.LastSeen) {
+		if torrent.SeedingTime >= 0 && !torrent.SeedingTimeEstimated {
+			// clients tracking the seeding time themselves exclude paused periods. Their counter restarts if the torrent
+			// is re-added.
+			if torrent.SeedingTime >= record.ClientSeedingTime {
+				record.SeedingTime += torrent.SeedingTime - record.ClientSeedingTime
+			} else {
+				record.SeedingTime += torrent.SeedingTime
+			}
+		} else if torrent.State == domain.TorrentStateSeeding {
+			// only the current state is known, so the whole gap is accounted to it
+			record.SeedingTime += min(at.Sub(record.LastSeen), store.maxObservationGap)
+		}
+		// the ratio resets if the torrent is re-added as well, so only increases count as uploads
+		if torrent.Ratio > record.Ratio {
+			record.Uploaded += int64((torrent.Ratio - record.Ratio) * float64(size))
+		}
+		record.LastSeen = at
+	}
+	record.Ratio = torrent.Ratio
+	record.ClientSeedingTime = torrent.SeedingTime
+	return record
+}
+
+// save writes the records to a temporary file first so that a crash does not corrupt the store.
+func (store *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(store.path), 0777); err != nil {
+		return fmt.Errorf("could not create seed store directory: %w", err)
+	}
+	tempPath := store.path + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("could not create seed store: %w", err)
+	}
+	if err = json.NewEncoder(file).Encode(store.records); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not encode seed store: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("could not write seed store: %w", err)
+	}
+	if err = os.Rename(tempPath, store.path); err != nil {
+		return fmt.Errorf("could not replace seed store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package seedstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/inventory"
+	"github.com/almanac1631/scrubarr/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Observe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed_store.json")
+	start := util.MustParseDate("2026-02-01 12:00:00")
+	files := []*domain.TorrentFile{{Path: "movie.mkv", Size: 1000}}
+	estimatedTorrent := &domain.TorrentEntry{
+		Client:               "rtorrent",
+		Id:                   "hash-1",
+		Ratio:                0.5,
+		State:                domain.TorrentStateSeeding,
+		Added:                start.Add(-time.Hour),
+		SeedingTime:          time.Hour,
+		SeedingTimeEstimated: true,
+		Files:                files,
+	}
+	trackedTorrent := &domain.TorrentEntry{
+		Client:      "deluge",
+		Id:          "hash-2",
+		Ratio:       1,
+		Added:       start.Add(-48 * time.Hour),
+		SeedingTime: 2 * time.Hour,
+		Files:       files,
+	}
+
+	store, err := NewStore(path, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	observations, err := store.Observe([]*domain.TorrentEntry{estimatedTorrent, trackedTorrent}, start)
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	assert.Equal(t, map[[2]string]inventory.SeedObservation{
+		{"rtorrent", "hash-1"}: {SeedingTime: time.Hour, Uploaded: 500},
+		{"deluge", "hash-2"}:   {SeedingTime: 2 * time.Hour, Uploaded: 1000},
+	}, observations)
+
+	// the torrents are re-added after a restart which resets the ratio and the seeding time of the clients
+	store, err = NewStore(path, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	estimatedTorrent.Ratio, estimatedTorrent.SeedingTime, estimatedTorrent.Added = 0.2, 0, start.Add(5*time.Hour)
+	trackedTorrent.Ratio, trackedTorrent.SeedingTime = 0.1, 30*time.Minute
+	observations, err = store.Observe([]*domain.TorrentEntry{estimatedTorrent, trackedTorrent}, start.Add(5*time.Hour))
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	assert.Equal(t, map[[2]string]inventory.SeedObservation{
+		// the gap while scrubarr was not running is capped
+		{"rtorrent", "hash-1"}: {SeedingTime: 3 * time.Hour, Uploaded: 500},
+		{"deluge", "hash-2"}:   {SeedingTime: 2*time.Hour + 30*time.Minute, Uploaded: 1000},
+	}, observations)
+
+	trackedTorrent.Ratio, trackedTorrent.SeedingTime = 0.6, time.Hour
+	observations, err = store.Observe([]*domain.TorrentEntry{trackedTorrent}, start.Add(6*time.Hour))
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	assert.Equal(t, map[[2]string]inventory.SeedObservation{
+		{"deluge", "hash-2"}: {SeedingTime: 3 * time.Hour, Uploaded: 1500},
+	}, observations)
+
+	// paused torrents do not accumulate any seeding time
+	estimatedTorrent.State = domain.TorrentStatePaused
+	observations, err = store.Observe([]*domain.TorrentEntry{estimatedTorrent}, start.Add(7*time.Hour))
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	assert.Equal(t, map[[2]string]inventory.SeedObservation{
+		{"rtorrent", "hash-1"}: {SeedingTime: 3 * time.Hour, Uploaded: 500},
+	}, observations)
+}
@@ -0,0 +1,51 @@
+package torrentclients
+
+import (
+	"context"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+var _ domain.TorrentSource = (*DegradedTorrentSource)(nil)
+
+// DegradedTorrentSource stands in for a torrent source that could not be set up. It delegates to the connected source
+// once it has been reconnected in the background. Until then, every call fails with domain.ErrSourceDegraded.
+type DegradedTorrentSource struct {
+	name   string
+	source *domain.DegradedSource[domain.TorrentSource]
+}
+
+func NewDegradedTorrentSource(ctx context.Context, name string, connectErr error, connect func() (domain.TorrentSource, error), retryInterval time.Duration) *DegradedTorrentSource {
+	return &DegradedTorrentSource{name, domain.NewDegradedSource(ctx, name, connectErr, connect, retryInterval)}
+}
+
+func (degradedSource *DegradedTorrentSource) GetTorrentEntries(ctx context.Context) ([]*domain.TorrentEntry, error) {
+	source, err := degradedSource.source.Get()
+	if err != nil {
+		return nil, err
+	}
+	return source.GetTorrentEntries(ctx)
+}
+
+func (degradedSource *DegradedTorrentSource) DeleteTorrent(ctx context.Context, id string, mode domain.RemovalMode) error {
+	source, err := degradedSource.source.Get()
+	if err != nil {
+		return err
+	}
+	return source.DeleteTorrent(ctx, id, mode)
+}
+
+// SupportsRemovalMode delegates to the connected source. As long as the source is degraded, no mode is supported as
+// deleting torrents fails anyway.
+func (degradedSource *DegradedTorrentSource) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	source, err := degradedSource.source.Get()
+	if err != nil {
+		return false
+	}
+	return source.SupportsRemovalMode(mode)
+}
+
+func (degradedSource *DegradedTorrentSource) Name() string {
+	return degradedSource.name
+}
@@ -1,9 +1,11 @@
 package torrentclients
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
@@ -13,39 +15,122 @@ import (
 var _ domain.TorrentSource = (*DelugeRetriever)(nil)
 
 type DelugeRetriever struct {
-	client *delugeclient.ClientV2
-	dryRun bool
+	name      string
+	transport delugeTransport
+	// seedOnlyLabel is assigned to torrents deleted using domain.RemovalModeRelabel
+	seedOnlyLabel string
+	dryRun        bool
 }
 
-func NewDelugeRetriever(hostname string, port uint, username string, password string, dryRun bool) (*DelugeRetriever, error) {
+// delugeTransport abstracts the daemon rpc api and the json api of deluge-web. Both request the same status keys, so
+// the resulting torrent entries are identical. The deluge client library used by the daemon transport does not support
+// contexts, so its calls are only bounded by the connection timeout.
+type delugeTransport interface {
+	getTorrentStatuses(ctx context.Context) (map[string]delugeTorrentStatus, error)
+	// removeTorrent removes the torrent and optionally its data. It returns domain.ErrTorrentNotFound for unknown
+	// torrents.
+	removeTorrent(ctx context.Context, id string, removeData bool) error
+	// pauseTorrent pauses the torrent. It returns domain.ErrTorrentNotFound for unknown torrents.
+	pauseTorrent(ctx context.Context, id string) error
+	// setTorrentLabel assigns the label to the torrent and creates it if necessary. It requires the label plugin and
+	// returns domain.ErrTorrentNotFound for unknown torrents.
+	setTorrentLabel(ctx context.Context, id string, label string) error
+}
+
+// delugeTorrentStatus contains the status keys of a torrent as returned by core.get_torrents_status.
+type delugeTorrentStatus struct {
+	Name          string                 `json:"name"`
+	State         string                 `json:"state"`
+	TrackerHost   string                 `json:"tracker_host"`
+	TrackerStatus string                 `json:"tracker_status"`
+	Ratio         float64                `json:"ratio"`
+	SeedingTime   int64                  `json:"seeding_time"`
+	SavePath      string                 `json:"save_path"`
+	CompletedTime int64                  `json:"completed_time"`
+	Files         []delugeTorrentFile    `json:"files"`
+	Trackers      []delugeTorrentTracker `json:"trackers"`
+	// Label is empty if the label plugin is disabled or no label is assigned.
+	Label string `json:"label"`
+}
+
+type delugeTorrentFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type delugeTorrentTracker struct {
+	Url string `json:"url"`
+}
+
+var delugeTorrentStatusKeys = []string{"name", "state", "tracker_host", "tracker_status", "ratio", "seeding_time",
+	"save_path", "completed_time", "files", "trackers", "label"}
+
+// NewDelugeRetriever connects to the rpc api of the deluge daemon.
+func NewDelugeRetriever(name string, hostname string, port uint, username string, password string, seedOnlyLabel string, timeout time.Duration, dryRun bool) (*DelugeRetriever, error) {
 	client := delugeclient.NewV2(delugeclient.Settings{
 		Hostname: hostname,
 		Port:     port,
 		Login:    username,
 		Password: password,
+		// the timeout applies to every read and write on the connection
+		ReadWriteTimeout: timeout,
 	})
 	err := client.Connect()
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to remote deluge rpc api: %w", err)
 	}
-	return &DelugeRetriever{client, dryRun}, nil
+	rpcClient := newDelugeRPCClient(hostname, port, username, password, timeout)
+	if err = rpcClient.connect(context.Background()); err != nil {
+		return nil, fmt.Errorf("could not connect to remote deluge rpc api: %w", err)
+	}
+	return &DelugeRetriever{name, &delugeDaemonTransport{client, rpcClient}, seedOnlyLabel, dryRun}, nil
 }
 
-func (retriever *DelugeRetriever) GetTorrentEntries() ([]*domain.TorrentEntry, error) {
-	torrentList, err := retriever.client.TorrentsStatus(delugeclient.StateSeeding, []string{})
+// NewDelugeWebRetriever connects to the json api of deluge-web, e.g. if the daemon port is not exposed. deluge-web only
+// uses a password.
+func NewDelugeWebRetriever(name string, baseUrl string, password string, seedOnlyLabel string, timeout time.Duration, dryRun bool) (*DelugeRetriever, error) {
+	transport, err := newDelugeWebTransport(baseUrl, password, timeout)
 	if err != nil {
-		return nil, fmt.Errorf("could not get torrent list from deluge rpc api: %w", err)
+		return nil, fmt.Errorf("could not connect to remote deluge web api: %w", err)
 	}
-	torrentEntries := make([]*domain.TorrentEntry, 0, len(torrentList))
-	for hash, torrent := range torrentList {
+	return &DelugeRetriever{name, transport, seedOnlyLabel, dryRun}, nil
+}
+
+func (retriever *DelugeRetriever) GetTorrentEntries(ctx context.Context) ([]*domain.TorrentEntry, error) {
+	torrentStatuses, err := retriever.transport.getTorrentStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get torrent list from deluge: %w", err)
+	}
+	torrentEntries := make([]*domain.TorrentEntry, 0, len(torrentStatuses))
+	for hash, torrent := range torrentStatuses {
 		torrentEntry := &domain.TorrentEntry{
-			Client:   retriever.Name(),
-			Id:       hash,
-			Name:     torrent.Name,
-			Added:    time.Unix(torrent.CompletedTime, 0).In(time.UTC),
-			Files:    []*domain.TorrentFile{},
-			Trackers: []string{torrent.TrackerHost},
-			Ratio:    float64(torrent.Ratio),
+			Client:        retriever.Name(),
+			Id:            hash,
+			Name:          torrent.Name,
+			State:         getDelugeTorrentState(torrent.State),
+			Files:         []*domain.TorrentFile{},
+			Trackers:      []string{},
+			TrackerStatus: torrent.TrackerStatus,
+			Labels:        []string{},
+			Ratio:         torrent.Ratio,
+			SeedingTime:   time.Duration(torrent.SeedingTime) * time.Second,
+			DownloadDir:   torrent.SavePath,
+		}
+		// completed_time is 0 for torrents that have not been completed yet
+		if torrent.CompletedTime > 0 {
+			torrentEntry.Added = time.Unix(torrent.CompletedTime, 0).In(time.UTC)
+		}
+		for _, tracker := range torrent.Trackers {
+			if trackerUrl := domain.NormalizeTrackerUrl(tracker.Url); !slices.Contains(torrentEntry.Trackers, trackerUrl) {
+				torrentEntry.Trackers = append(torrentEntry.Trackers, trackerUrl)
+			}
+		}
+		// the tracker list might be missing if the torrent was added in between both calls
+		if len(torrentEntry.Trackers) == 0 && torrent.TrackerHost != "" {
+			torrentEntry.Trackers = append(torrentEntry.Trackers, domain.NormalizeTrackerUrl(torrent.TrackerHost))
+		}
+		if torrent.Label != "" {
+			torrentEntry.Labels = append(torrentEntry.Labels, torrent.Label)
 		}
 		for _, file := range torrent.Files {
 			torrentEntry.Files = append(torrentEntry.Files, &domain.TorrentFile{
@@ -58,15 +143,107 @@ func (retriever *DelugeRetriever) GetTorrentEntries() ([]*domain.TorrentEntry, e
 	return torrentEntries, nil
 }
 
-func (retriever *DelugeRetriever) DeleteTorrent(id string) error {
+func getDelugeTorrentState(state string) domain.TorrentState {
+	switch delugeclient.TorrentState(state) {
+	case delugeclient.StateSeeding:
+		return domain.TorrentStateSeeding
+	case delugeclient.StateDownloading:
+		return domain.TorrentStateDownloading
+	case delugeclient.StatePaused:
+		return domain.TorrentStatePaused
+	case delugeclient.StateQueued:
+		return domain.TorrentStateQueued
+	case delugeclient.StateAllocating, delugeclient.StateChecking, delugeclient.StateMoving:
+		return domain.TorrentStateChecking
+	case delugeclient.StateError:
+		return domain.TorrentStateError
+	default:
+		return domain.TorrentStateUnknown
+	}
+}
+
+func (retriever *DelugeRetriever) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	switch mode {
+	case domain.RemovalModeRemoveWithData, domain.RemovalModeRemoveKeepData, domain.RemovalModePause, domain.RemovalModeRelabel:
+		return true
+	default:
+		return false
+	}
+}
+
+func (retriever *DelugeRetriever) DeleteTorrent(ctx context.Context, id string, mode domain.RemovalMode) error {
 	if retriever.dryRun {
-		slog.Info("[DRY RUN] Skipping deluge torrent deletion.", "id", id)
+		slog.Info("[DRY RUN] Skipping deluge torrent deletion.", "id", id, "mode", mode)
 		return nil
 	}
-	ok, err := retriever.client.RemoveTorrent(id, true)
+	switch mode {
+	case domain.RemovalModeRemoveWithData:
+		return retriever.transport.removeTorrent(ctx, id, true)
+	case domain.RemovalModeRemoveKeepData:
+		return retriever.transport.removeTorrent(ctx, id, false)
+	case domain.RemovalModePause:
+		return retriever.transport.pauseTorrent(ctx, id)
+	case domain.RemovalModeRelabel:
+		return retriever.transport.setTorrentLabel(ctx, id, retriever.seedOnlyLabel)
+	default:
+		return domain.ErrRemovalModeNotSupported
+	}
+}
+
+func (retriever *DelugeRetriever) Name() string {
+	return retriever.name
+}
+
+// delugeDaemonTransport uses the deluge client library and requests the trackers and the label which are not part of
+// its status keys separately.
+type delugeDaemonTransport struct {
+	client    *delugeclient.ClientV2
+	rpcClient *delugeRPCClient
+}
+
+func (transport *delugeDaemonTransport) getTorrentStatuses(ctx context.Context) (map[string]delugeTorrentStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	torrentList, err := transport.client.TorrentsStatus(delugeclient.StateUnspecified, []string{})
 	if err != nil {
-		var wrappedErr delugeclient.RPCError
-		if errors.As(err, &wrappedErr) && wrappedErr.ExceptionType == "InvalidTorrentError" {
+		return nil, fmt.Errorf("could not get torrent list from deluge rpc api: %w", err)
+	}
+	torrentDetails, err := transport.rpcClient.getTorrentDetails(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get torrent details from deluge rpc api: %w", err)
+	}
+	torrentStatuses := make(map[string]delugeTorrentStatus, len(torrentList))
+	for hash, torrent := range torrentList {
+		torrentStatus := delugeTorrentStatus{
+			Name:          torrent.Name,
+			State:         torrent.State,
+			TrackerHost:   torrent.TrackerHost,
+			TrackerStatus: torrent.TrackerStatus,
+			Ratio:         float64(torrent.Ratio),
+			SeedingTime:   torrent.SeedingTime,
+			SavePath:      torrent.SavePath,
+			CompletedTime: torrent.CompletedTime,
+			Label:         torrentDetails[hash].label,
+		}
+		for _, file := range torrent.Files {
+			torrentStatus.Files = append(torrentStatus.Files, delugeTorrentFile{Path: file.Path, Size: file.Size})
+		}
+		for _, tracker := range torrentDetails[hash].trackers {
+			torrentStatus.Trackers = append(torrentStatus.Trackers, delugeTorrentTracker{Url: tracker})
+		}
+		torrentStatuses[hash] = torrentStatus
+	}
+	return torrentStatuses, nil
+}
+
+func (transport *delugeDaemonTransport) removeTorrent(ctx context.Context, id string, removeData bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ok, err := transport.client.RemoveTorrent(id, removeData)
+	if err != nil {
+		if isDelugeTorrentNotFoundError(err) {
 			return domain.ErrTorrentNotFound
 		}
 		return fmt.Errorf("could not remove torrent from deluge rpc api: %w", err)
@@ -76,6 +253,55 @@ func (retriever *DelugeRetriever) DeleteTorrent(id string) error {
 	return nil
 }
 
-func (retriever *DelugeRetriever) Name() string {
-	return "deluge"
+func (transport *delugeDaemonTransport) pauseTorrent(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := transport.client.PauseTorrents(id); err != nil {
+		if isDelugeTorrentNotFoundError(err) {
+			return domain.ErrTorrentNotFound
+		}
+		return fmt.Errorf("could not pause torrent on deluge rpc api: %w", err)
+	}
+	return nil
+}
+
+func (transport *delugeDaemonTransport) setTorrentLabel(ctx context.Context, id string, label string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	labelPlugin, err := transport.client.LabelPlugin()
+	if err != nil {
+		return fmt.Errorf("could not get enabled plugins from deluge rpc api: %w", err)
+	} else if labelPlugin == nil {
+		return errors.New("could not set torrent label as the label plugin of deluge is not enabled")
+	}
+	// the status of unknown torrents is empty which the client library cannot decode, so the list filtered by the id is
+	// requested instead
+	torrentStatuses, err := transport.client.TorrentsStatus(delugeclient.StateUnspecified, []string{id})
+	if err != nil {
+		return fmt.Errorf("could not check torrent %q on deluge rpc api: %w", id, err)
+	} else if len(torrentStatuses) == 0 {
+		return domain.ErrTorrentNotFound
+	}
+	labels, err := labelPlugin.GetLabels()
+	if err != nil {
+		return fmt.Errorf("could not get labels from deluge rpc api: %w", err)
+	}
+	if !slices.Contains(labels, label) {
+		if err = labelPlugin.AddLabel(label); err != nil {
+			return fmt.Errorf("could not add label %q on deluge rpc api: %w", label, err)
+		}
+	}
+	if err = labelPlugin.SetTorrentLabel(id, label); err != nil {
+		return fmt.Errorf("could not set label of torrent %q on deluge rpc api: %w", id, err)
+	}
+	return nil
+}
+
+// isDelugeTorrentNotFoundError reports whether the daemon rejected the call because of an unknown torrent id. Pausing
+// unknown torrents fails with a KeyError instead of an InvalidTorrentError.
+func isDelugeTorrentNotFoundError(err error) bool {
+	var wrappedErr delugeclient.RPCError
+	return errors.As(err, &wrappedErr) && (wrappedErr.ExceptionType == "InvalidTorrentError" || wrappedErr.ExceptionType == "KeyError")
 }
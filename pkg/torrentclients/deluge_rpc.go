@@ -0,0 +1,240 @@
+package torrentclients
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gdm85/go-rencode"
+)
+
+const (
+	delugeProtocolVersion = 1
+	delugeClientVersion   = "2.0.3"
+
+	delugeMessageResponse = 1
+	delugeMessageError    = 2
+	delugeMessageEvent    = 3
+)
+
+// delugeRPCClient is a minimal client for the rpc protocol of deluge 2 daemons. It covers calls the deluge client
+// library does not support, e.g. requesting status keys like trackers which are not part of its fixed key list.
+type delugeRPCClient struct {
+	address  string
+	username string
+	password string
+	// timeout bounds dialing and every call unless the context of the call expires earlier
+	timeout time.Duration
+
+	mutex  sync.Mutex
+	conn   net.Conn
+	serial int64
+}
+
+func newDelugeRPCClient(hostname string, port uint, username string, password string, timeout time.Duration) *delugeRPCClient {
+	return &delugeRPCClient{
+		address:  net.JoinHostPort(hostname, strconv.FormatUint(uint64(port), 10)),
+		username: username,
+		password: password,
+		timeout:  timeout,
+	}
+}
+
+func (client *delugeRPCClient) connect(ctx context.Context) error {
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: client.timeout}, Config: &tls.Config{
+		// deluge daemons use self-signed certificates
+		InsecureSkipVerify: true,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", client.address)
+	if err != nil {
+		return fmt.Errorf("could not connect to deluge daemon: %w", err)
+	}
+	client.conn = conn
+	var kwargs rencode.Dictionary
+	kwargs.Add("client_version", delugeClientVersion)
+	if _, err = client.request(ctx, "daemon.login", rencode.NewList(client.username, client.password), kwargs); err != nil {
+		client.close()
+		return fmt.Errorf("could not login to deluge daemon: %w", err)
+	}
+	return nil
+}
+
+func (client *delugeRPCClient) close() {
+	if client.conn != nil {
+		_ = client.conn.Close()
+		client.conn = nil
+	}
+}
+
+// call executes the given method and returns its return value. The connection is established lazily and dropped on
+// transport errors, so the next call reconnects.
+func (client *delugeRPCClient) call(ctx context.Context, method string, args rencode.List, kwargs rencode.Dictionary) (interface{}, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if client.conn == nil {
+		if err := client.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return client.request(ctx, method, args, kwargs)
+}
+
+func (client *delugeRPCClient) request(ctx context.Context, method string, args rencode.List, kwargs rencode.Dictionary) (interface{}, error) {
+	client.serial++
+	var body bytes.Buffer
+	zlibWriter := zlib.NewWriter(&body)
+	encoder := rencode.NewEncoder(zlibWriter)
+	// the payload is wrapped twice as the protocol supports multiple calls per request
+	if err := encoder.Encode(rencode.NewList(rencode.NewList(client.serial, method, args, kwargs))); err != nil {
+		return nil, fmt.Errorf("could not encode deluge rpc request: %w", err)
+	}
+	if err := zlibWriter.Close(); err != nil {
+		return nil, fmt.Errorf("could not compress deluge rpc request: %w", err)
+	}
+	header := make([]byte, 5)
+	header[0] = delugeProtocolVersion
+	binary.BigEndian.PutUint32(header[1:], uint32(body.Len()))
+
+	deadline := time.Now().Add(client.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := client.conn.SetDeadline(deadline); err != nil {
+		client.close()
+		return nil, fmt.Errorf("could not set deadline of deluge rpc connection: %w", err)
+	}
+	// interrupt pending reads and writes once the context is cancelled
+	conn := client.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+	if _, err := client.conn.Write(append(header, body.Bytes()...)); err != nil {
+		client.close()
+		return nil, fmt.Errorf("could not send deluge rpc request: %w", err)
+	}
+	for {
+		response, err := client.readMessage()
+		if err != nil {
+			client.close()
+			return nil, err
+		}
+		var messageType, requestId int64
+		if err = response.Scan(&messageType); err != nil {
+			return nil, fmt.Errorf("could not read deluge rpc message type: %w", err)
+		}
+		response.Shift(1)
+		// events are only sent to clients registered for them
+		if messageType == delugeMessageEvent {
+			continue
+		}
+		if err = response.Scan(&requestId); err != nil {
+			return nil, fmt.Errorf("could not read deluge rpc request id: %w", err)
+		}
+		response.Shift(1)
+		if requestId != client.serial {
+			client.close()
+			return nil, fmt.Errorf("got deluge rpc response for request %d but expected %d", requestId, client.serial)
+		}
+		switch messageType {
+		case delugeMessageResponse:
+			if response.Length() != 1 {
+				return nil, errors.New("got deluge rpc response without return value")
+			}
+			return response.Values()[0], nil
+		case delugeMessageError:
+			var exceptionType string
+			if err = response.Scan(&exceptionType); err != nil {
+				return nil, fmt.Errorf("could not read deluge rpc error: %w", err)
+			}
+			return nil, fmt.Errorf("deluge rpc call %s failed with %s", method, exceptionType)
+		default:
+			return nil, fmt.Errorf("got deluge rpc message with unknown type %d", messageType)
+		}
+	}
+}
+
+func (client *delugeRPCClient) readMessage() (rencode.List, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(client.conn, header); err != nil {
+		return rencode.List{}, fmt.Errorf("could not read deluge rpc response header: %w", err)
+	}
+	if header[0] != delugeProtocolVersion {
+		return rencode.List{}, fmt.Errorf("got deluge rpc protocol version %d but expected %d", header[0], delugeProtocolVersion)
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(client.conn, body); err != nil {
+		return rencode.List{}, fmt.Errorf("could not read deluge rpc response: %w", err)
+	}
+	zlibReader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return rencode.List{}, fmt.Errorf("could not decompress deluge rpc response: %w", err)
+	}
+	var message rencode.List
+	if err = rencode.NewDecoder(zlibReader).Scan(&message); err != nil {
+		return rencode.List{}, fmt.Errorf("could not decode deluge rpc response: %w", err)
+	}
+	return message, nil
+}
+
+type delugeRPCTorrentDetails struct {
+	trackers []string
+	// label is empty if the label plugin is disabled
+	label string
+}
+
+// getTorrentDetails returns the announce urls and the label of all torrents by their hash.
+func (client *delugeRPCClient) getTorrentDetails(ctx context.Context) (map[string]delugeRPCTorrentDetails, error) {
+	var filter rencode.Dictionary
+	result, err := client.call(ctx, "core.get_torrents_status", rencode.NewList(filter, rencode.NewList("trackers", "label")), rencode.Dictionary{})
+	if err != nil {
+		return nil, err
+	}
+	resultDict, ok := result.(rencode.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("got unexpected torrent status type %T", result)
+	}
+	torrentStatuses, err := resultDict.Zip()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse torrent statuses: %w", err)
+	}
+	torrentDetails := make(map[string]delugeRPCTorrentDetails, len(torrentStatuses))
+	for hash, torrentStatusRaw := range torrentStatuses {
+		torrentStatus, ok := torrentStatusRaw.(rencode.Dictionary)
+		if !ok {
+			return nil, fmt.Errorf("got unexpected status type %T for torrent %q", torrentStatusRaw, hash)
+		}
+		var details delugeRPCTorrentDetails
+		if label, ok := torrentStatus.Get("label"); ok {
+			if labelBytes, ok := label.([]byte); ok {
+				details.label = string(labelBytes)
+			}
+		}
+		trackersRaw, _ := torrentStatus.Get("trackers")
+		trackerList, _ := trackersRaw.(rencode.List)
+		trackers := make([]string, 0, trackerList.Length())
+		for _, trackerRaw := range trackerList.Values() {
+			tracker, ok := trackerRaw.(rencode.Dictionary)
+			if !ok {
+				continue
+			}
+			if trackerUrl, ok := tracker.Get("url"); ok {
+				if trackerUrlBytes, ok := trackerUrl.([]byte); ok {
+					trackers = append(trackers, string(trackerUrlBytes))
+				}
+			}
+		}
+		details.trackers = trackers
+		torrentDetails[hash] = details
+	}
+	return torrentDetails, nil
+}
@@ -0,0 +1,69 @@
+package torrentclients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gdm85/go-rencode"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_delugeRPCClient_getTorrentDetails(t *testing.T) {
+	torrents := newFakeDelugeTorrents()
+	// the label is missing if the label plugin is disabled
+	delete(torrents[delugeDownloadingHash], "label")
+	daemon, port := newFakeDelugeDaemon(t, torrents)
+	// events sent in between are skipped
+	daemon.events = true
+	client := newDelugeRPCClient("127.0.0.1", port, "admin", "secret", time.Minute)
+
+	torrentDetails, err := client.getTorrentDetails(context.Background())
+	if err != nil {
+		t.Fatalf("getTorrentDetails() error = %v", err)
+	}
+	assert.Equal(t, map[string]delugeRPCTorrentDetails{
+		delugeSeedingHash: {
+			trackers: []string{"https://tracker.example/announce?passkey=secret", "https://Tracker.example/announce?passkey=other"},
+			label:    "movies",
+		},
+		delugeDownloadingHash: {trackers: []string{}},
+	}, torrentDetails)
+	// the connection is established lazily and reused
+	assert.Equal(t, 1, daemon.logins)
+	_, err = client.getTorrentDetails(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, daemon.logins)
+}
+
+func Test_delugeRPCClient_call(t *testing.T) {
+	daemon, port := newFakeDelugeDaemon(t, map[string]map[string]any{})
+
+	client := newDelugeRPCClient("127.0.0.1", port, "admin", "wrong", time.Minute)
+	_, err := client.call(context.Background(), "daemon.info", rencode.List{}, rencode.Dictionary{})
+	assert.ErrorContains(t, err, "could not login to deluge daemon: deluge rpc call daemon.login failed with BadLoginError")
+	assert.Nil(t, client.conn)
+
+	client = newDelugeRPCClient("127.0.0.1", port, "admin", "secret", time.Minute)
+	_, err = client.call(context.Background(), "core.unknown_method", rencode.List{}, rencode.Dictionary{})
+	assert.ErrorContains(t, err, "deluge rpc call core.unknown_method failed with AttributeError")
+	// exceptions of calls do not affect the connection
+	assert.NotNil(t, client.conn)
+	assert.Equal(t, 1, daemon.logins)
+
+	daemon.brokenResponses = 1
+	_, err = client.call(context.Background(), "core.get_enabled_plugins", rencode.List{}, rencode.Dictionary{})
+	assert.ErrorContains(t, err, "got deluge rpc protocol version 2 but expected 1")
+	assert.Nil(t, client.conn)
+	// the next call reconnects
+	plugins, err := client.call(context.Background(), "core.get_enabled_plugins", rencode.List{}, rencode.Dictionary{})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Label"}, fromRencode(plugins))
+	assert.Equal(t, 2, daemon.logins)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.call(ctx, "core.get_enabled_plugins", rencode.List{}, rencode.Dictionary{})
+	assert.Error(t, err)
+	assert.Nil(t, client.conn)
+}
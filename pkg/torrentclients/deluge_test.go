@@ -0,0 +1,437 @@
+package torrentclients
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"maps"
+	"math/big"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/gdm85/go-rencode"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDelugeDaemon implements the rpc protocol of deluge 2 daemons for a fixed list of torrents. The statuses contain
+// all keys requested by the deluge client library and the rpc client.
+type fakeDelugeDaemon struct {
+	listener net.Listener
+
+	mutex    sync.Mutex
+	conns    []net.Conn
+	torrents map[string]map[string]any
+	labels   []string
+	logins   int
+	// events makes the daemon send an event before every response
+	events bool
+	// brokenResponses is the number of next responses sent with an unknown protocol version
+	brokenResponses int
+	// removedData is the remove_data parameter of the last removal
+	removedData bool
+}
+
+func newFakeDelugeDaemon(t testing.TB, torrents map[string]map[string]any) (*fakeDelugeDaemon, uint) {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{newTestCertificate(t)}})
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	daemon := &fakeDelugeDaemon{listener: listener, torrents: torrents}
+	t.Cleanup(daemon.close)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			daemon.mutex.Lock()
+			daemon.conns = append(daemon.conns, conn)
+			daemon.mutex.Unlock()
+			go daemon.serve(conn)
+		}
+	}()
+	return daemon, uint(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (daemon *fakeDelugeDaemon) close() {
+	_ = daemon.listener.Close()
+	daemon.mutex.Lock()
+	defer daemon.mutex.Unlock()
+	for _, conn := range daemon.conns {
+		_ = conn.Close()
+	}
+}
+
+// serve answers the requests of a single connection which has to log in first.
+func (daemon *fakeDelugeDaemon) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	authenticated := false
+	for {
+		request, err := readFakeDelugeRequest(conn)
+		if err != nil {
+			return
+		}
+		var serial int64
+		var method string
+		var args rencode.List
+		if err = request.Scan(&serial, &method, &args); err != nil {
+			return
+		}
+		daemon.mutex.Lock()
+		result, exceptionType := daemon.call(method, fromRencode(args).([]any), &authenticated)
+		sendEvent := daemon.events
+		protocolVersion := byte(delugeProtocolVersion)
+		if daemon.brokenResponses > 0 {
+			daemon.brokenResponses--
+			protocolVersion++
+		}
+		daemon.mutex.Unlock()
+		if sendEvent {
+			event := rencode.NewList(delugeMessageEvent, "TorrentStateChangedEvent", rencode.NewList("hash", "Paused"))
+			if err = writeFakeDelugeMessage(conn, delugeProtocolVersion, event); err != nil {
+				return
+			}
+		}
+		response := rencode.NewList(delugeMessageResponse, serial, toRencode(result))
+		if exceptionType != "" {
+			response = rencode.NewList(delugeMessageError, serial, exceptionType, rencode.NewList("message"), rencode.Dictionary{}, "traceback")
+		}
+		if err = writeFakeDelugeMessage(conn, protocolVersion, response); err != nil {
+			return
+		}
+	}
+}
+
+// call executes the method and returns its result or the type of the raised exception.
+func (daemon *fakeDelugeDaemon) call(method string, args []any, authenticated *bool) (any, string) {
+	if method == "daemon.login" {
+		if args[0] != "admin" || args[1] != "secret" {
+			return nil, "BadLoginError"
+		}
+		*authenticated = true
+		daemon.logins++
+		return 10, ""
+	} else if !*authenticated {
+		return nil, "NotAuthorizedError"
+	}
+	switch method {
+	case "core.get_torrents_status":
+		ids, _ := args[0].(map[string]any)["id"].([]any)
+		statuses := make(map[string]any, len(daemon.torrents))
+		for hash, torrent := range daemon.torrents {
+			if ids == nil || slices.Contains(ids, any(hash)) {
+				statuses[hash] = getFakeDelugeTorrentStatus(torrent, args[1].([]any))
+			}
+		}
+		return statuses, ""
+	case "core.get_enabled_plugins":
+		return []any{"Label"}, ""
+	case "label.get_labels":
+		return daemon.labels, ""
+	case "label.add":
+		daemon.labels = append(daemon.labels, args[0].(string))
+		return nil, ""
+	case "label.set_torrent":
+		daemon.torrents[args[0].(string)]["label"] = args[1]
+		return nil, ""
+	case "core.pause_torrents":
+		for _, hash := range args[0].([]any) {
+			torrent, ok := daemon.torrents[hash.(string)]
+			if !ok {
+				return nil, "KeyError"
+			}
+			torrent["state"] = "Paused"
+		}
+		return nil, ""
+	case "core.remove_torrent":
+		if _, ok := daemon.torrents[args[0].(string)]; !ok {
+			return nil, "InvalidTorrentError"
+		}
+		delete(daemon.torrents, args[0].(string))
+		daemon.removedData = args[1].(bool)
+		return true, ""
+	default:
+		return nil, "AttributeError"
+	}
+}
+
+func getFakeDelugeTorrentStatus(torrent map[string]any, keys []any) map[string]any {
+	status := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := torrent[key.(string)]; ok {
+			status[key.(string)] = value
+		}
+	}
+	return status
+}
+
+// readFakeDelugeRequest returns the single request of a message sent by the client.
+func readFakeDelugeRequest(conn net.Conn) (rencode.List, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return rencode.List{}, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return rencode.List{}, err
+	}
+	zlibReader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return rencode.List{}, err
+	}
+	var requests rencode.List
+	if err = rencode.NewDecoder(zlibReader).Scan(&requests); err != nil {
+		return rencode.List{}, err
+	}
+	var request rencode.List
+	if err = requests.Scan(&request); err != nil {
+		return rencode.List{}, err
+	}
+	return request, nil
+}
+
+func writeFakeDelugeMessage(conn net.Conn, protocolVersion byte, message rencode.List) error {
+	var body bytes.Buffer
+	zlibWriter := zlib.NewWriter(&body)
+	encoder := rencode.NewEncoder(zlibWriter)
+	if err := encoder.Encode(message); err != nil {
+		return err
+	}
+	if err := zlibWriter.Close(); err != nil {
+		return err
+	}
+	header := make([]byte, 5)
+	header[0] = protocolVersion
+	binary.BigEndian.PutUint32(header[1:], uint32(body.Len()))
+	_, err := conn.Write(append(header, body.Bytes()...))
+	return err
+}
+
+// toRencode converts json like values to rencode values. Floats are encoded with 32 bits like deluge does by default.
+func toRencode(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		var dict rencode.Dictionary
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			dict.Add(key, toRencode(value[key]))
+		}
+		return dict
+	case []any:
+		var list rencode.List
+		for _, item := range value {
+			list.Add(toRencode(item))
+		}
+		return list
+	case []string:
+		var list rencode.List
+		for _, item := range value {
+			list.Add(item)
+		}
+		return list
+	case float64:
+		return float32(value)
+	default:
+		return value
+	}
+}
+
+// fromRencode converts rencode values to json like values.
+func fromRencode(value any) any {
+	switch value := value.(type) {
+	case rencode.Dictionary:
+		dict := make(map[string]any, value.Length())
+		for i, key := range value.Keys() {
+			dict[fromRencode(key).(string)] = fromRencode(value.Values()[i])
+		}
+		return dict
+	case rencode.List:
+		list := make([]any, 0, value.Length())
+		for _, item := range value.Values() {
+			list = append(list, fromRencode(item))
+		}
+		return list
+	case []byte:
+		return string(value)
+	default:
+		return value
+	}
+}
+
+// newTestCertificate returns a self-signed certificate like the ones generated by deluge daemons.
+func newTestCertificate(t testing.TB) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{certificate}, PrivateKey: key}
+}
+
+const (
+	delugeSeedingHash     = "0123456789abcdef0123456789abcdef01234567"
+	delugeDownloadingHash = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+// newFakeDelugeTorrents returns the full statuses of a seeding and a downloading torrent as reported by deluge. The
+// fake daemon and the fake deluge-web return the same statuses, so both transports are expected to result in the
+// torrent entries of getWantDelugeTorrentEntries.
+func newFakeDelugeTorrents() map[string]map[string]any {
+	newTorrent := func(status map[string]any) map[string]any {
+		torrent := map[string]any{
+			"save_path":             "/downloads",
+			"download_location":     "/downloads",
+			"next_announce":         0,
+			"num_seeds":             0,
+			"total_seeds":           0,
+			"num_peers":             0,
+			"total_peers":           0,
+			"eta":                   0,
+			"download_payload_rate": 0,
+			"upload_payload_rate":   0,
+			"distributed_copies":    0.0,
+			"num_pieces":            1,
+			"file_priorities":       []any{1},
+			"peers":                 []any{},
+			"active_time":           7200,
+			"time_added":            1767222000,
+			"last_seen_complete":    0,
+			"private":               true,
+		}
+		maps.Copy(torrent, status)
+		return torrent
+	}
+	return map[string]map[string]any{
+		delugeSeedingHash: newTorrent(map[string]any{
+			"name":           "Some Movie",
+			"state":          "Seeding",
+			"tracker_host":   "tracker.example",
+			"tracker_status": "Announce OK",
+			"ratio":          1.25,
+			"seeding_time":   3600,
+			"completed_time": 1767225600,
+			"files":          []any{map[string]any{"index": 0, "path": "Some Movie/movie.mkv", "size": 1000, "offset": 0}},
+			// both urls are the same after redacting the passkeys
+			"trackers": []any{
+				map[string]any{"url": "https://tracker.example/announce?passkey=secret", "tier": 0},
+				map[string]any{"url": "https://Tracker.example/announce?passkey=other", "tier": 1},
+			},
+			"label":         "movies",
+			"total_size":    1000,
+			"total_done":    1000,
+			"piece_length":  1000,
+			"progress":      100.0,
+			"file_progress": []any{1.0},
+			"is_seed":       true,
+			"is_finished":   true,
+		}),
+		delugeDownloadingHash: newTorrent(map[string]any{
+			"name":           "Other Movie",
+			"state":          "Downloading",
+			"tracker_host":   "Other.example",
+			"tracker_status": "",
+			"ratio":          0.0,
+			"seeding_time":   0,
+			"completed_time": 0,
+			"files":          []any{map[string]any{"index": 0, "path": "Other Movie/movie.mkv", "size": 2000, "offset": 0}},
+			// the tracker list is empty if the torrent was added in between the calls of the daemon transport
+			"trackers":      []any{},
+			"label":         "",
+			"total_size":    2000,
+			"total_done":    1000,
+			"piece_length":  2000,
+			"progress":      50.0,
+			"file_progress": []any{0.5},
+			"is_seed":       false,
+			"is_finished":   false,
+		}),
+	}
+}
+
+// getWantDelugeTorrentEntries returns the torrent entries expected for newFakeDelugeTorrents regardless of the
+// transport.
+func getWantDelugeTorrentEntries() []*domain.TorrentEntry {
+	return []*domain.TorrentEntry{{
+		Client:        "deluge",
+		Id:            delugeSeedingHash,
+		Name:          "Some Movie",
+		State:         domain.TorrentStateSeeding,
+		Files:         []*domain.TorrentFile{{Path: "Some Movie/movie.mkv", Size: 1000}},
+		Trackers:      []string{"https://tracker.example/announce?passkey=REDACTED"},
+		TrackerStatus: "Announce OK",
+		Labels:        []string{"movies"},
+		Ratio:         1.25,
+		SeedingTime:   time.Hour,
+		Added:         time.Unix(1767225600, 0).In(time.UTC),
+		DownloadDir:   "/downloads",
+	}, {
+		Client:      "deluge",
+		Id:          delugeDownloadingHash,
+		Name:        "Other Movie",
+		State:       domain.TorrentStateDownloading,
+		Files:       []*domain.TorrentFile{{Path: "Other Movie/movie.mkv", Size: 2000}},
+		Trackers:    []string{"other.example"},
+		Labels:      []string{},
+		DownloadDir: "/downloads",
+	}}
+}
+
+func TestDelugeRetriever(t *testing.T) {
+	daemon, port := newFakeDelugeDaemon(t, newFakeDelugeTorrents())
+
+	_, err := NewDelugeRetriever("deluge", "127.0.0.1", port, "admin", "wrong", "seed-only", time.Minute, false)
+	assert.ErrorContains(t, err, "BadLoginError")
+
+	retriever, err := NewDelugeRetriever("deluge", "127.0.0.1", port, "admin", "secret", "seed-only", time.Minute, false)
+	if err != nil {
+		t.Fatalf("NewDelugeRetriever() error = %v", err)
+	}
+	// the client library and the rpc client use separate connections
+	assert.Equal(t, 2, daemon.logins)
+
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	assert.ElementsMatch(t, getWantDelugeTorrentEntries(), torrentEntries)
+
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModePause))
+	assert.Equal(t, "Paused", daemon.torrents[delugeSeedingHash]["state"])
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "fedcba9876543210fedcba9876543210fedcba98", domain.RemovalModePause), domain.ErrTorrentNotFound)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRelabel))
+	assert.Equal(t, []string{"seed-only"}, daemon.labels)
+	assert.Equal(t, "seed-only", daemon.torrents[delugeSeedingHash]["label"])
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "fedcba9876543210fedcba9876543210fedcba98", domain.RemovalModeRelabel), domain.ErrTorrentNotFound)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRemoveWithData))
+	assert.True(t, daemon.removedData)
+	assert.NotContains(t, daemon.torrents, delugeSeedingHash)
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRemoveKeepData), domain.ErrTorrentNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = retriever.GetTorrentEntries(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
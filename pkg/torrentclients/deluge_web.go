@@ -0,0 +1,224 @@
+package torrentclients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+// delugeWebErrorNotAuthenticated is the error code of deluge-web for requests without a valid session cookie.
+const delugeWebErrorNotAuthenticated = 1
+
+var _ delugeTransport = (*delugeWebTransport)(nil)
+
+// delugeWebTransport uses the json api of deluge-web with cookie based sessions. deluge-web proxies core calls to the
+// daemon it is connected to.
+type delugeWebTransport struct {
+	client   *http.Client
+	jsonUrl  string
+	password string
+	// mutex guards requestId and the session renewal
+	mutex     sync.Mutex
+	requestId int64
+}
+
+type delugeWebRequest struct {
+	Method string `json:"method"`
+	Params []any  `json:"params"`
+	Id     int64  `json:"id"`
+}
+
+type delugeWebResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *delugeWebError `json:"error"`
+	Id     int64           `json:"id"`
+}
+
+type delugeWebError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (err *delugeWebError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", err.Message, err.Code)
+}
+
+func newDelugeWebTransport(baseUrl string, password string, timeout time.Duration) (*delugeWebTransport, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cookie jar for deluge web api: %w", err)
+	}
+	transport := &delugeWebTransport{
+		client:   &http.Client{Jar: jar, Timeout: timeout},
+		jsonUrl:  strings.TrimSuffix(baseUrl, "/") + "/json",
+		password: password,
+	}
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	if err = transport.login(context.Background()); err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
+
+// login creates a new session and connects deluge-web to the first configured daemon if it is not connected yet.
+func (transport *delugeWebTransport) login(ctx context.Context) error {
+	var loggedIn bool
+	if err := transport.request(ctx, "auth.login", []any{transport.password}, &loggedIn); err != nil {
+		return fmt.Errorf("could not send login request: %w", err)
+	}
+	if !loggedIn {
+		return errors.New("login rejected")
+	}
+	var connected bool
+	if err := transport.request(ctx, "web.connected", []any{}, &connected); err != nil {
+		return fmt.Errorf("could not check daemon connection: %w", err)
+	}
+	if connected {
+		return nil
+	}
+	// every host is an array of id, hostname, port and username
+	var hosts [][]any
+	if err := transport.request(ctx, "web.get_hosts", []any{}, &hosts); err != nil {
+		return fmt.Errorf("could not get daemon hosts: %w", err)
+	}
+	if len(hosts) == 0 || len(hosts[0]) == 0 {
+		return errors.New("deluge web is not connected and has no daemon hosts configured")
+	}
+	if err := transport.request(ctx, "web.connect", []any{hosts[0][0]}, nil); err != nil {
+		return fmt.Errorf("could not connect to daemon %v: %w", hosts[0][0], err)
+	}
+	return nil
+}
+
+// call sends the given method and decodes its result into receivingValue. An expired session is renewed once before
+// giving up.
+func (transport *delugeWebTransport) call(ctx context.Context, method string, params []any, receivingValue any) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	err := transport.request(ctx, method, params, receivingValue)
+	var webErr *delugeWebError
+	if errors.As(err, &webErr) && webErr.Code == delugeWebErrorNotAuthenticated {
+		if err = transport.login(ctx); err != nil {
+			return fmt.Errorf("could not renew deluge web session: %w", err)
+		}
+		err = transport.request(ctx, method, params, receivingValue)
+	}
+	return err
+}
+
+func (transport *delugeWebTransport) request(ctx context.Context, method string, params []any, receivingValue any) error {
+	transport.requestId++
+	requestBody, err := json.Marshal(delugeWebRequest{Method: method, Params: params, Id: transport.requestId})
+	if err != nil {
+		return fmt.Errorf("could not encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, transport.jsonUrl, bytes.NewReader(requestBody))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := transport.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response (status code: %d): %q", resp.StatusCode, string(body))
+	}
+	var response delugeWebResponse
+	if err = json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("could not decode response of %q: %w", method, err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if receivingValue == nil {
+		return nil
+	}
+	if err = json.Unmarshal(response.Result, receivingValue); err != nil {
+		return fmt.Errorf("could not decode result of %q: %w", method, err)
+	}
+	return nil
+}
+
+func (transport *delugeWebTransport) getTorrentStatuses(ctx context.Context) (map[string]delugeTorrentStatus, error) {
+	var torrentStatuses map[string]delugeTorrentStatus
+	if err := transport.call(ctx, "core.get_torrents_status", []any{map[string]any{}, delugeTorrentStatusKeys}, &torrentStatuses); err != nil {
+		return nil, fmt.Errorf("could not get torrent list from deluge web api: %w", err)
+	}
+	return torrentStatuses, nil
+}
+
+// checkTorrentExists returns domain.ErrTorrentNotFound if the torrent is unknown to the daemon. deluge-web does not
+// pass on the exception types of the daemon, so they cannot be used instead.
+func (transport *delugeWebTransport) checkTorrentExists(ctx context.Context, id string) error {
+	// the status of unknown torrents is empty
+	var torrentStatus map[string]any
+	if err := transport.call(ctx, "core.get_torrent_status", []any{id, []string{"name"}}, &torrentStatus); err != nil {
+		return fmt.Errorf("could not check torrent %q on deluge web api: %w", id, err)
+	}
+	if len(torrentStatus) == 0 {
+		return domain.ErrTorrentNotFound
+	}
+	return nil
+}
+
+func (transport *delugeWebTransport) removeTorrent(ctx context.Context, id string, removeData bool) error {
+	if err := transport.checkTorrentExists(ctx, id); err != nil {
+		return err
+	}
+	var removed bool
+	if err := transport.call(ctx, "core.remove_torrent", []any{id, removeData}, &removed); err != nil {
+		return fmt.Errorf("could not remove torrent from deluge web api: %w", err)
+	} else if !removed {
+		return fmt.Errorf("could not remove torrent from deluge web api but no error was thrown")
+	}
+	return nil
+}
+
+func (transport *delugeWebTransport) pauseTorrent(ctx context.Context, id string) error {
+	if err := transport.checkTorrentExists(ctx, id); err != nil {
+		return err
+	}
+	if err := transport.call(ctx, "core.pause_torrents", []any{[]string{id}}, nil); err != nil {
+		return fmt.Errorf("could not pause torrent on deluge web api: %w", err)
+	}
+	return nil
+}
+
+func (transport *delugeWebTransport) setTorrentLabel(ctx context.Context, id string, label string) error {
+	if err := transport.checkTorrentExists(ctx, id); err != nil {
+		return err
+	}
+	var labels []string
+	if err := transport.call(ctx, "label.get_labels", []any{}, &labels); err != nil {
+		return fmt.Errorf("could not get labels from deluge web api (is the label plugin enabled?): %w", err)
+	}
+	if !slices.Contains(labels, label) {
+		if err := transport.call(ctx, "label.add", []any{label}, nil); err != nil {
+			return fmt.Errorf("could not add label %q on deluge web api: %w", label, err)
+		}
+	}
+	if err := transport.call(ctx, "label.set_torrent", []any{id, label}, nil); err != nil {
+		return fmt.Errorf("could not set label of torrent %q on deluge web api: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package torrentclients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDelugeWebServer implements the json api of deluge-web for the torrents of newFakeDelugeTorrents.
+type fakeDelugeWebServer struct {
+	sessionId string
+	connected bool
+	paused    bool
+	removed   bool
+	// removedData is the remove_data parameter of the last removal
+	removedData bool
+	labels      []string
+	// torrentLabel is the label set using label.set_torrent
+	torrentLabel string
+}
+
+func (server *fakeDelugeWebServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request delugeWebRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result any
+	var webErr *delugeWebError
+	cookie, _ := r.Cookie("_session_id")
+	authenticated := cookie != nil && cookie.Value == server.sessionId
+	switch {
+	case request.Method == "auth.login":
+		result = request.Params[0] == "secret"
+		if result == true {
+			server.sessionId = "session-" + time.Now().String()
+			http.SetCookie(w, &http.Cookie{Name: "_session_id", Value: server.sessionId, Path: "/"})
+		}
+	case !authenticated:
+		webErr = &delugeWebError{Message: "Not authenticated", Code: delugeWebErrorNotAuthenticated}
+	case request.Method == "web.connected":
+		result = server.connected
+	case request.Method == "web.get_hosts":
+		result = [][]any{{"host-1", "127.0.0.1", 58846, "localclient"}}
+	case request.Method == "web.connect":
+		server.connected = request.Params[0] == "host-1"
+	case request.Method == "core.get_torrents_status":
+		statuses := make(map[string]any)
+		for hash, torrent := range newFakeDelugeTorrents() {
+			statuses[hash] = getFakeDelugeTorrentStatus(torrent, request.Params[1].([]any))
+		}
+		result = statuses
+	case request.Method == "core.get_torrent_status":
+		result = map[string]any{}
+		if !server.removed && request.Params[0] == delugeSeedingHash {
+			result = map[string]any{"name": "Some Movie"}
+		}
+	case request.Method == "label.get_labels":
+		result = server.labels
+	case request.Method == "label.add":
+		server.labels = append(server.labels, request.Params[0].(string))
+	case request.Method == "label.set_torrent":
+		server.torrentLabel = request.Params[1].(string)
+	case request.Method == "core.pause_torrents":
+		server.paused = true
+	case request.Method == "core.remove_torrent":
+		server.removed = true
+		server.removedData = request.Params[1] == true
+		result = true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "error": webErr, "id": request.Id})
+}
+
+func TestDelugeWebRetriever(t *testing.T) {
+	server := &fakeDelugeWebServer{}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	_, err := NewDelugeWebRetriever("deluge", httpServer.URL+"/", "wrong", "seed-only", time.Minute, false)
+	assert.Error(t, err)
+
+	retriever, err := NewDelugeWebRetriever("deluge", httpServer.URL+"/", "secret", "seed-only", time.Minute, false)
+	if err != nil {
+		t.Fatalf("NewDelugeWebRetriever() error = %v", err)
+	}
+	assert.True(t, server.connected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = retriever.GetTorrentEntries(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the session expired in the meantime
+	server.sessionId = "expired"
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	assert.ElementsMatch(t, getWantDelugeTorrentEntries(), torrentEntries)
+
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModePause))
+	assert.True(t, server.paused)
+	assert.False(t, server.removed)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRelabel))
+	assert.Equal(t, []string{"seed-only"}, server.labels)
+	assert.Equal(t, "seed-only", server.torrentLabel)
+	assert.False(t, server.removed)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRemoveKeepData))
+	assert.True(t, server.removed)
+	assert.False(t, server.removedData)
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), delugeSeedingHash, domain.RemovalModeRemoveWithData), domain.ErrTorrentNotFound)
+}
@@ -1,6 +1,7 @@
 package torrentclients
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -31,9 +32,9 @@ func NewDefaultTorrentManager(retrievers ...domain.TorrentSource) *DefaultTorren
 	return manager
 }
 
-func (manager *DefaultTorrentManager) GetTorrents() ([]*domain.TorrentEntry, error) {
+func (manager *DefaultTorrentManager) GetTorrents(ctx context.Context) ([]*domain.TorrentEntry, error) {
 	if manager.Entries == nil {
-		if err := manager.RefreshCache(); err != nil {
+		if err := manager.RefreshCache(ctx); err != nil && !errors.Is(err, domain.ErrSourceDegraded) {
 			return nil, err
 		}
 	}
@@ -47,15 +48,26 @@ func (manager *DefaultTorrentManager) GetTorrents() ([]*domain.TorrentEntry, err
 	return torrentList, nil
 }
 
-func (manager *DefaultTorrentManager) DeleteTorrent(client, id string) error {
+// DeleteTorrent deletes the torrent using the given mode. Paused and relabeled torrents stay in the cache.
+func (manager *DefaultTorrentManager) DeleteTorrent(ctx context.Context, client, id string, mode domain.RemovalMode) error {
 	manager.entryLock.Lock()
 	defer manager.entryLock.Unlock()
 	retriever, ok := manager.retrievers[client]
 	if !ok {
 		return fmt.Errorf("could not find retriever for %q", client)
 	}
-	if err := retriever.DeleteTorrent(id); err != nil {
-		return fmt.Errorf("could not delete torrent %q from client %q: %w", id, client, err)
+	if err := retriever.DeleteTorrent(ctx, id, mode); err != nil {
+		return fmt.Errorf("could not delete torrent %q from client %q (mode: %s): %w", id, client, mode, err)
+	}
+	if mode == domain.RemovalModePause {
+		for _, entry := range manager.Entries[client] {
+			if entry.Id == id {
+				entry.State = domain.TorrentStatePaused
+			}
+		}
+		return nil
+	} else if mode == domain.RemovalModeRelabel {
+		return nil
 	}
 	manager.Entries[client] = slices.DeleteFunc(manager.Entries[client], func(entrySearch *domain.TorrentEntry) bool {
 		return entrySearch.Id == id
@@ -63,34 +75,59 @@ func (manager *DefaultTorrentManager) DeleteTorrent(client, id string) error {
 	return nil
 }
 
-func (manager *DefaultTorrentManager) RefreshCache() error {
+func (manager *DefaultTorrentManager) SupportsRemovalMode(client string, mode domain.RemovalMode) bool {
+	retriever, ok := manager.retrievers[client]
+	return ok && retriever.SupportsRemovalMode(mode)
+}
+
+// RefreshCache fetches the torrent entries of all clients. Clients that fail keep their previously known entries. If
+// all of them failed because they could not be reached, the returned error wraps domain.ErrSourceDegraded.
+func (manager *DefaultTorrentManager) RefreshCache(ctx context.Context) error {
 	manager.entryLock.Lock()
 	defer manager.entryLock.Unlock()
 	entryLock := &sync.Mutex{}
+	previousEntries := manager.Entries
 	manager.Entries = make(map[string][]*domain.TorrentEntry)
 	errChan := make(chan error)
 	defer close(errChan)
 	for name, retriever := range manager.retrievers {
 		go func() {
 			slog.Debug("Refreshing torrent cache...", "client", name)
-			retrieverEntries, err := retriever.GetTorrentEntries()
+			retrieverEntries, err := retriever.GetTorrentEntries(ctx)
 			if err == nil {
 				entryLock.Lock()
 				defer entryLock.Unlock()
 				manager.Entries[name] = retrieverEntries
 			}
 			if err != nil {
-				err = fmt.Errorf("could not get torrent entries for client %q: %w", name, err)
+				entryLock.Lock()
+				defer entryLock.Unlock()
+				if entries, ok := previousEntries[name]; ok {
+					manager.Entries[name] = entries
+				}
+				if domain.IsConnectionError(err) {
+					err = fmt.Errorf("%w: could not get torrent entries for client %q: %w", domain.ErrSourceDegraded, name, err)
+				} else {
+					err = fmt.Errorf("could not get torrent entries for client %q: %w", name, err)
+				}
 			}
 			errChan <- err
 			slog.Debug("Refreshed torrent cache", "client", name)
 		}()
 	}
-	var err error
+	// the degraded errors are only returned on their own, so that any other error fails the refresh
+	var err, degradedErr error
 	for i := 0; i < len(manager.retrievers); i++ {
-		err = errors.Join(err, <-errChan)
+		if retrieverErr := <-errChan; errors.Is(retrieverErr, domain.ErrSourceDegraded) {
+			degradedErr = errors.Join(degradedErr, retrieverErr)
+		} else {
+			err = errors.Join(err, retrieverErr)
+		}
+	}
+	if err != nil {
+		return err
 	}
-	return err
+	return degradedErr
 }
 
 func (manager *DefaultTorrentManager) SaveCache(writer io.Writer) error {
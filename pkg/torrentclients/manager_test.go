@@ -0,0 +1,49 @@
+package torrentclients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// staticTorrentSource returns the given torrent entries or fails with the given error.
+type staticTorrentSource struct {
+	domain.TorrentSource
+	name           string
+	torrentEntries []*domain.TorrentEntry
+	err            error
+}
+
+func (source *staticTorrentSource) GetTorrentEntries(context.Context) ([]*domain.TorrentEntry, error) {
+	return source.torrentEntries, source.err
+}
+
+func (source *staticTorrentSource) Name() string {
+	return source.name
+}
+
+func TestDefaultTorrentManager_RefreshCache(t *testing.T) {
+	deluge := &staticTorrentSource{name: "deluge", torrentEntries: []*domain.TorrentEntry{{Client: "deluge", Id: "deluge-torrent"}}}
+	qbittorrent := &staticTorrentSource{name: "qbittorrent", torrentEntries: []*domain.TorrentEntry{{Client: "qbittorrent", Id: "qbittorrent-torrent"}}}
+	manager := NewDefaultTorrentManager(deluge, qbittorrent)
+	assert.NoError(t, manager.RefreshCache(context.Background()))
+
+	// unreachable clients keep their previous entries
+	deluge.err = fmt.Errorf("could not get torrents: %w", io.ErrUnexpectedEOF)
+	err := manager.RefreshCache(context.Background())
+	assert.ErrorIs(t, err, domain.ErrSourceDegraded)
+	assert.Equal(t, deluge.torrentEntries, manager.Entries["deluge"])
+
+	// any other error fails the refresh even if another client is degraded
+	parseErr := errors.New("could not parse torrents")
+	qbittorrent.err = parseErr
+	err = manager.RefreshCache(context.Background())
+	assert.ErrorIs(t, err, parseErr)
+	assert.NotErrorIs(t, err, domain.ErrSourceDegraded)
+	assert.Equal(t, qbittorrent.torrentEntries, manager.Entries["qbittorrent"])
+}
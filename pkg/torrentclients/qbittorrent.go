@@ -0,0 +1,312 @@
+package torrentclients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/util"
+)
+
+var _ domain.TorrentSource = (*QbittorrentRetriever)(nil)
+
+var errQbittorrentForbidden = errors.New("qbittorrent webui api access forbidden")
+
+var errQbittorrentNotFound = errors.New("qbittorrent webui api resource not found")
+
+type QbittorrentRetriever struct {
+	name     string
+	client   *http.Client
+	baseUrl  string
+	username string
+	password string
+	// parallelism is the maximum number of torrents whose files and trackers are requested concurrently.
+	parallelism int
+	dryRun      bool
+}
+
+type qbittorrentTorrent struct {
+	Hash         string  `json:"hash"`
+	Name         string  `json:"name"`
+	Ratio        float64 `json:"ratio"`
+	CompletionOn int64   `json:"completion_on"`
+	SavePath     string  `json:"save_path"`
+	SeedingTime  int64   `json:"seeding_time"`
+	State        string  `json:"state"`
+	Category     string  `json:"category"`
+	// Tags are separated by a comma and a space
+	Tags string `json:"tags"`
+}
+
+type qbittorrentFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+type qbittorrentTracker struct {
+	Url string `json:"url"`
+}
+
+func NewQbittorrentRetriever(name string, baseUrl string, username string, password string, timeout time.Duration, parallelism int, dryRun bool) (*QbittorrentRetriever, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cookie jar for qbittorrent webui api: %w", err)
+	}
+	retriever := &QbittorrentRetriever{
+		name:        name,
+		client:      &http.Client{Jar: jar, Timeout: timeout},
+		baseUrl:     baseUrl,
+		username:    username,
+		password:    password,
+		parallelism: max(parallelism, 1),
+		dryRun:      dryRun,
+	}
+	if err = retriever.login(context.Background()); err != nil {
+		return nil, fmt.Errorf("could not connect to remote qbittorrent webui api: %w", err)
+	}
+	return retriever, nil
+}
+
+func (retriever *QbittorrentRetriever) GetTorrentEntries(ctx context.Context) ([]*domain.TorrentEntry, error) {
+	var torrentList []qbittorrentTorrent
+	if err := retriever.get(ctx, "torrents/info", nil, &torrentList); err != nil {
+		return nil, fmt.Errorf("could not get torrent list from qbittorrent webui api: %w", err)
+	}
+	// the webui api only supports retrieving the files and trackers of a single torrent per request
+	torrentEntries, err := util.MapConcurrently(ctx, torrentList, retriever.parallelism, retriever.getTorrentEntry)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(torrentEntries, func(torrentEntry *domain.TorrentEntry) bool {
+		return torrentEntry == nil
+	}), nil
+}
+
+// getTorrentEntry requests the files and trackers of the given torrent. It returns nil if the torrent has been removed
+// since the torrent list was requested.
+func (retriever *QbittorrentRetriever) getTorrentEntry(ctx context.Context, torrent qbittorrentTorrent) (*domain.TorrentEntry, error) {
+	torrentEntry := &domain.TorrentEntry{
+		Client:      retriever.Name(),
+		Id:          torrent.Hash,
+		Name:        torrent.Name,
+		Files:       []*domain.TorrentFile{},
+		Trackers:    []string{},
+		Labels:      getQbittorrentLabels(torrent),
+		Ratio:       torrent.Ratio,
+		State:       getQbittorrentTorrentState(torrent.State),
+		SeedingTime: time.Duration(torrent.SeedingTime) * time.Second,
+		DownloadDir: torrent.SavePath,
+	}
+	// completion_on is -1 for torrents that have not been completed yet
+	if torrent.CompletionOn > 0 {
+		torrentEntry.Added = time.Unix(torrent.CompletionOn, 0).In(time.UTC)
+	}
+	query := url.Values{"hash": {torrent.Hash}}
+	var torrentFiles []qbittorrentFile
+	err := retriever.get(ctx, "torrents/files", query, &torrentFiles)
+	if errors.Is(err, errQbittorrentNotFound) {
+		slog.Debug("Torrent has been removed from qbittorrent in the meantime.", "hash", torrent.Hash)
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get torrent files from qbittorrent webui api: %w", err)
+	}
+	for _, torrentFile := range torrentFiles {
+		torrentEntry.Files = append(torrentEntry.Files, &domain.TorrentFile{
+			Path: torrentFile.Name,
+			Size: torrentFile.Size,
+		})
+	}
+	var torrentTrackers []qbittorrentTracker
+	err = retriever.get(ctx, "torrents/trackers", query, &torrentTrackers)
+	if errors.Is(err, errQbittorrentNotFound) {
+		slog.Debug("Torrent has been removed from qbittorrent in the meantime.", "hash", torrent.Hash)
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get torrent trackers from qbittorrent webui api: %w", err)
+	}
+	for _, tracker := range torrentTrackers {
+		// skip pseudo trackers like "** [DHT] **", "** [PeX] **" and "** [LSD] **"
+		if strings.HasPrefix(tracker.Url, "** [") {
+			continue
+		}
+		if trackerUrl := domain.NormalizeTrackerUrl(tracker.Url); !slices.Contains(torrentEntry.Trackers, trackerUrl) {
+			torrentEntry.Trackers = append(torrentEntry.Trackers, trackerUrl)
+		}
+	}
+	return torrentEntry, nil
+}
+
+// getQbittorrentLabels returns the category followed by the tags of the torrent.
+func getQbittorrentLabels(torrent qbittorrentTorrent) []string {
+	labels := make([]string, 0)
+	if torrent.Category != "" {
+		labels = append(labels, torrent.Category)
+	}
+	for _, tag := range strings.Split(torrent.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(labels, tag) {
+			labels = append(labels, tag)
+		}
+	}
+	return labels
+}
+
+// getQbittorrentTorrentState maps the states of the webui api. qBittorrent 5 renamed the paused states to stopped.
+func getQbittorrentTorrentState(state string) domain.TorrentState {
+	switch state {
+	case "uploading", "stalledUP", "forcedUP":
+		return domain.TorrentStateSeeding
+	case "downloading", "stalledDL", "forcedDL", "metaDL", "forcedMetaDL":
+		return domain.TorrentStateDownloading
+	case "pausedUP", "pausedDL", "stoppedUP", "stoppedDL":
+		return domain.TorrentStatePaused
+	case "queuedUP", "queuedDL":
+		return domain.TorrentStateQueued
+	case "checkingUP", "checkingDL", "checkingResumeData", "allocating", "moving":
+		return domain.TorrentStateChecking
+	case "error", "missingFiles":
+		return domain.TorrentStateError
+	default:
+		return domain.TorrentStateUnknown
+	}
+}
+
+// SupportsRemovalMode only reports domain.RemovalModeRemoveWithData as supported as the other modes are not implemented
+// for this client.
+func (retriever *QbittorrentRetriever) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	return mode == domain.RemovalModeRemoveWithData
+}
+
+func (retriever *QbittorrentRetriever) DeleteTorrent(ctx context.Context, id string, mode domain.RemovalMode) error {
+	if !retriever.SupportsRemovalMode(mode) {
+		return domain.ErrRemovalModeNotSupported
+	}
+	hash := strings.ToLower(id)
+	var torrentList []qbittorrentTorrent
+	if err := retriever.get(ctx, "torrents/info", url.Values{"hashes": {hash}}, &torrentList); err != nil {
+		return fmt.Errorf("could not check torrent %q on qbittorrent webui api: %w", hash, err)
+	}
+	if len(torrentList) == 0 {
+		return domain.ErrTorrentNotFound
+	}
+	if retriever.dryRun {
+		slog.Info("[DRY RUN] Skipping qbittorrent torrent deletion.", "hash", hash)
+		return nil
+	}
+	form := url.Values{"hashes": {hash}, "deleteFiles": {"true"}}
+	if err := retriever.post(ctx, "torrents/delete", form); err != nil {
+		return fmt.Errorf("could not remove torrent %q from qbittorrent webui api: %w", hash, err)
+	}
+	return nil
+}
+
+func (retriever *QbittorrentRetriever) Name() string {
+	return retriever.name
+}
+
+func (retriever *QbittorrentRetriever) login(ctx context.Context) error {
+	form := url.Values{"username": {retriever.username}, "password": {retriever.password}}
+	resp, err := retriever.postForm(ctx, "auth/login", form)
+	if err != nil {
+		return fmt.Errorf("could not send login request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read login response: %w", err)
+	}
+	// qbittorrent answers with 200 and "Fails." on invalid credentials
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("login rejected (status code: %d): %q", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// get requests the given api method and decodes the json response into receivingValue. An expired session is renewed
+// once before giving up.
+func (retriever *QbittorrentRetriever) get(ctx context.Context, method string, query url.Values, receivingValue any) error {
+	requestUrl := retriever.endpoint(method)
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	body, err := retriever.doWithLogin(ctx, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		return retriever.client.Do(req)
+	})
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, receivingValue); err != nil {
+		return fmt.Errorf("could not decode response of %q: %w", method, err)
+	}
+	return nil
+}
+
+func (retriever *QbittorrentRetriever) post(ctx context.Context, method string, form url.Values) error {
+	_, err := retriever.doWithLogin(ctx, func() (*http.Response, error) {
+		return retriever.postForm(ctx, method, form)
+	})
+	return err
+}
+
+func (retriever *QbittorrentRetriever) postForm(ctx context.Context, method string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, retriever.endpoint(method), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return retriever.client.Do(req)
+}
+
+func (retriever *QbittorrentRetriever) doWithLogin(ctx context.Context, do func() (*http.Response, error)) ([]byte, error) {
+	body, err := readQbittorrentResponse(do())
+	if errors.Is(err, errQbittorrentForbidden) {
+		if err = retriever.login(ctx); err != nil {
+			return nil, fmt.Errorf("could not renew qbittorrent session: %w", err)
+		}
+		body, err = readQbittorrentResponse(do())
+	}
+	return body, err
+}
+
+func readQbittorrentResponse(resp *http.Response, err error) ([]byte, error) {
+	if err != nil {
+		return nil, fmt.Errorf("could not send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, errQbittorrentForbidden
+	}
+	// e.g. torrents/files answers with 404 for unknown hashes
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %q", errQbittorrentNotFound, string(body))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response (status code: %d): %q", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+func (retriever *QbittorrentRetriever) endpoint(method string) string {
+	return strings.TrimSuffix(retriever.baseUrl, "/") + "/api/v2/" + method
+}
@@ -0,0 +1,197 @@
+package torrentclients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeQbittorrentServer implements the webui api of qbittorrent for a fixed list of torrents.
+type fakeQbittorrentServer struct {
+	sessionId string
+	logins    int
+	torrents  []map[string]any
+	// deleteFiles is the deleteFiles parameter of the last deletion
+	deleteFiles string
+	// removedHashes are listed by torrents/info but unknown to the other methods like torrents removed in the meantime
+	removedHashes []string
+	// brokenHashes fail with an internal server error on the methods other than torrents/info
+	brokenHashes []string
+}
+
+func (server *fakeQbittorrentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v2/auth/login" {
+		if r.PostFormValue("username") != "admin" || r.PostFormValue("password") != "secret" {
+			_, _ = w.Write([]byte("Fails."))
+			return
+		}
+		server.logins++
+		server.sessionId = fmt.Sprintf("session-%d", server.logins)
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: server.sessionId, Path: "/"})
+		_, _ = w.Write([]byte("Ok."))
+		return
+	}
+	cookie, _ := r.Cookie("SID")
+	if cookie == nil || cookie.Value != server.sessionId {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if hash := r.URL.Query().Get("hash"); slices.Contains(server.removedHashes, hash) {
+		http.Error(w, "Torrent hash was not found", http.StatusNotFound)
+		return
+	} else if slices.Contains(server.brokenHashes, hash) {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	var result any
+	switch r.URL.Path {
+	case "/api/v2/torrents/info":
+		torrents := make([]map[string]any, 0)
+		for _, torrent := range server.torrents {
+			if hashes := r.URL.Query().Get("hashes"); hashes == "" || hashes == torrent["hash"] {
+				torrents = append(torrents, torrent)
+			}
+		}
+		result = torrents
+	case "/api/v2/torrents/files":
+		result = []map[string]any{{"name": r.URL.Query().Get("hash") + "/movie.mkv", "size": 1000}}
+	case "/api/v2/torrents/trackers":
+		result = []map[string]any{
+			{"url": "** [DHT] **"},
+			{"url": "https://tracker.example/announce?passkey=secret"},
+			{"url": "https://tracker.example/announce?passkey=other"},
+		}
+	case "/api/v2/torrents/delete":
+		server.deleteFiles = r.PostFormValue("deleteFiles")
+		server.torrents = slices.DeleteFunc(server.torrents, func(torrent map[string]any) bool {
+			return torrent["hash"] == r.PostFormValue("hashes")
+		})
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func TestQbittorrentRetriever(t *testing.T) {
+	server := &fakeQbittorrentServer{torrents: []map[string]any{{
+		"hash":          "0123456789abcdef0123456789abcdef01234567",
+		"name":          "Some Movie",
+		"ratio":         1.5,
+		"completion_on": 1767225600,
+		"save_path":     "/downloads",
+		"seeding_time":  3600,
+		"state":         "stalledUP",
+		"category":      "movies",
+		"tags":          "keep, movies",
+	}, {
+		"hash":          "89abcdef0123456789abcdef0123456789abcdef",
+		"name":          "Other Movie",
+		"completion_on": -1,
+		"state":         "stoppedDL",
+	}}}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	_, err := NewQbittorrentRetriever("qbittorrent", httpServer.URL+"/", "admin", "wrong", time.Minute, 2, false)
+	assert.ErrorContains(t, err, "login rejected")
+
+	retriever, err := NewQbittorrentRetriever("qbittorrent", httpServer.URL+"/", "admin", "secret", time.Minute, 2, false)
+	if err != nil {
+		t.Fatalf("NewQbittorrentRetriever() error = %v", err)
+	}
+	assert.Equal(t, 1, server.logins)
+
+	// the session expired in the meantime and is renewed after the first 403
+	server.sessionId = "expired"
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	assert.Equal(t, 2, server.logins)
+	assert.Equal(t, []*domain.TorrentEntry{{
+		Client:      "qbittorrent",
+		Id:          "0123456789abcdef0123456789abcdef01234567",
+		Name:        "Some Movie",
+		State:       domain.TorrentStateSeeding,
+		Files:       []*domain.TorrentFile{{Path: "0123456789abcdef0123456789abcdef01234567/movie.mkv", Size: 1000}},
+		Trackers:    []string{"https://tracker.example/announce?passkey=REDACTED"},
+		Labels:      []string{"movies", "keep"},
+		Ratio:       1.5,
+		SeedingTime: time.Hour,
+		Added:       time.Unix(1767225600, 0).In(time.UTC),
+		DownloadDir: "/downloads",
+	}, {
+		Client:   "qbittorrent",
+		Id:       "89abcdef0123456789abcdef0123456789abcdef",
+		Name:     "Other Movie",
+		State:    domain.TorrentStatePaused,
+		Files:    []*domain.TorrentFile{{Path: "89abcdef0123456789abcdef0123456789abcdef/movie.mkv", Size: 1000}},
+		Trackers: []string{"https://tracker.example/announce?passkey=REDACTED"},
+		Labels:   []string{},
+	}}, torrentEntries)
+
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "0123456789ABCDEF0123456789ABCDEF01234567", domain.RemovalModePause), domain.ErrRemovalModeNotSupported)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), "0123456789ABCDEF0123456789ABCDEF01234567", domain.RemovalModeRemoveWithData))
+	assert.Equal(t, "true", server.deleteFiles)
+	assert.Len(t, server.torrents, 1)
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "0123456789abcdef0123456789abcdef01234567", domain.RemovalModeRemoveWithData), domain.ErrTorrentNotFound)
+}
+
+func TestQbittorrentRetriever_GetTorrentEntries_removed(t *testing.T) {
+	server := &fakeQbittorrentServer{torrents: []map[string]any{
+		{"hash": "0123456789abcdef0123456789abcdef01234567", "name": "Removed Movie", "state": "uploading"},
+		{"hash": "89abcdef0123456789abcdef0123456789abcdef", "name": "Some Movie", "state": "uploading"},
+	}, removedHashes: []string{"0123456789abcdef0123456789abcdef01234567"}}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	retriever, err := NewQbittorrentRetriever("qbittorrent", httpServer.URL, "admin", "secret", time.Minute, 2, false)
+	if err != nil {
+		t.Fatalf("NewQbittorrentRetriever() error = %v", err)
+	}
+
+	// torrents removed after being listed are skipped
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	assert.Len(t, torrentEntries, 1)
+	assert.Equal(t, "89abcdef0123456789abcdef0123456789abcdef", torrentEntries[0].Id)
+
+	// any other error fails the retrieval
+	server.brokenHashes = []string{"89abcdef0123456789abcdef0123456789abcdef"}
+	torrentEntries, err = retriever.GetTorrentEntries(context.Background())
+	assert.ErrorContains(t, err, "could not get torrent files from qbittorrent webui api: unexpected response (status code: 500)")
+	assert.Nil(t, torrentEntries)
+}
+
+func Test_getQbittorrentTorrentState(t *testing.T) {
+	tests := []struct {
+		state string
+		want  domain.TorrentState
+	}{
+		{"uploading", domain.TorrentStateSeeding},
+		{"forcedUP", domain.TorrentStateSeeding},
+		{"metaDL", domain.TorrentStateDownloading},
+		{"pausedUP", domain.TorrentStatePaused},
+		{"stoppedUP", domain.TorrentStatePaused},
+		{"queuedDL", domain.TorrentStateQueued},
+		{"moving", domain.TorrentStateChecking},
+		{"missingFiles", domain.TorrentStateError},
+		{"unknown", domain.TorrentStateUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			assert.Equal(t, tt.want, getQbittorrentTorrentState(tt.state))
+		})
+	}
+}
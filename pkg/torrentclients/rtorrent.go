@@ -4,96 +4,415 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
 	"github.com/autobrr/go-rtorrent"
+	"github.com/autobrr/go-rtorrent/xmlrpc"
 )
 
 var _ domain.TorrentSource = (*RtorrentRetriever)(nil)
 
 type RtorrentRetriever struct {
+	name   string
 	client *rtorrent.Client
-	dryRun bool
+	// xmlrpcClient is used for calls not covered by the rtorrent client
+	xmlrpcClient *xmlrpc.Client
+	// seedOnlyLabel is assigned to torrents deleted using domain.RemovalModeRelabel
+	seedOnlyLabel string
+	dryRun        bool
 }
 
-func NewRtorrentRetriever(hostname string, username string, password string, dryRun bool) (*RtorrentRetriever, error) {
-	client := rtorrent.NewClient(rtorrent.Config{
+// NewRtorrentRetriever connects to the xmlrpc api of rtorrent. The hostname is either the url of an http endpoint like
+// ruTorrent's RPC2 using basic auth or an scgi://host:port or unix:///path/to/socket url of an SCGI socket.
+func NewRtorrentRetriever(name string, hostname string, username string, password string, seedOnlyLabel string, timeout time.Duration, dryRun bool) (*RtorrentRetriever, error) {
+	config := rtorrent.Config{
 		Addr:      hostname,
 		BasicUser: username,
 		BasicPass: password,
-	})
+	}
+	xmlrpcConfig := xmlrpc.Config{
+		Addr:      hostname,
+		BasicUser: username,
+		BasicPass: password,
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if isScgiUrl(hostname) {
+		transport, err := newScgiTransport(hostname)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = transport
+	}
+	xmlrpcConfig.Client = httpClient
+	client := rtorrent.NewClientWithOpts(config, rtorrent.WithCustomClient(httpClient))
 	_, err := client.Name(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to remote rtorrent rpc api: %w", err)
 	}
-	return &RtorrentRetriever{client, dryRun}, nil
+	xmlrpcClient := xmlrpc.NewClient(xmlrpcConfig)
+	return &RtorrentRetriever{name, client, xmlrpcClient, seedOnlyLabel, dryRun}, nil
+}
+
+// rtorrentMulticallBatchSize is the number of torrents whose files and trackers are requested in a single
+// system.multicall round trip.
+const rtorrentMulticallBatchSize = 250
+
+// rtorrentTorrentFields are the d.multicall2 commands whose results are parsed by parseRtorrentTorrent in this order.
+var rtorrentTorrentFields = []interface{}{"d.hash=", "d.name=", "d.directory=", "d.complete=", "d.ratio=",
+	"d.timestamp.finished=", "d.message=", "d.custom=seedingtime", "d.state=", "d.is_active=", "d.hashing=", "d.custom1="}
+
+type rtorrentTorrent struct {
+	hash          string
+	name          string
+	directory     string
+	completed     bool
+	ratio         float64
+	finished      time.Time
+	trackerStatus string
+	seedingSince  time.Time
+	started       bool
+	active        bool
+	hashing       bool
+	label         string
 }
 
-func (retriever *RtorrentRetriever) GetTorrentEntries() ([]*domain.TorrentEntry, error) {
-	torrentList, err := retriever.client.GetTorrents(context.Background(), rtorrent.ViewMain)
+// getTorrents returns all torrents of the main view using a single d.multicall2 call. rtorrent does not track the
+// seeding time itself, so the seedingtime custom field set by ruTorrent is preferred over the finished timestamp. Both
+// include periods in which the torrent was stopped.
+func (retriever *RtorrentRetriever) getTorrents(ctx context.Context) ([]rtorrentTorrent, error) {
+	args := append([]interface{}{"", string(rtorrent.ViewMain)}, rtorrentTorrentFields...)
+	results, err := retriever.xmlrpcClient.Call(ctx, "d.multicall2", args...)
 	if err != nil {
-		return nil, fmt.Errorf("could not get torrent list from rtorrent: %w", err)
+		return nil, fmt.Errorf("d.multicall2 XMLRPC call failed: %w", err)
 	}
-	torrentEntries := make([]*domain.TorrentEntry, 0, len(torrentList))
-	for _, torrent := range torrentList {
-		torrentEntry := &domain.TorrentEntry{
-			Client:   retriever.Name(),
-			Id:       torrent.Hash,
-			Name:     torrent.Name,
-			Added:    torrent.Finished,
-			Files:    []*domain.TorrentFile{},
-			Trackers: []string{},
-			Ratio:    torrent.Ratio,
-		}
-		torrentFiles, err := retriever.client.GetFiles(context.Background(), torrent)
+	outerResults, ok := results.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("got unexpected d.multicall2 result %v", results)
+	}
+	torrents := make([]rtorrentTorrent, 0)
+	for _, outerResult := range outerResults {
+		innerResults, ok := outerResult.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("got unexpected d.multicall2 result %v", outerResult)
+		}
+		for _, innerResult := range innerResults {
+			torrent, err := parseRtorrentTorrent(innerResult)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse d.multicall2 row %v: %w", innerResult, err)
+			}
+			torrents = append(torrents, torrent)
+		}
+	}
+	return torrents, nil
+}
+
+// getRtorrentValue returns the value at index i of a multicall row if it has the expected type.
+func getRtorrentValue[T any](row []interface{}, i int) (T, error) {
+	var value T
+	if i >= len(row) {
+		return value, fmt.Errorf("got %d values, missing value %d", len(row), i)
+	}
+	value, ok := row[i].(T)
+	if !ok {
+		return value, fmt.Errorf("got value %v of unexpected type %T at %d", row[i], row[i], i)
+	}
+	return value, nil
+}
+
+// parseRtorrentTorrent parses a row of the d.multicall2 call requesting rtorrentTorrentFields.
+func parseRtorrentTorrent(row interface{}) (rtorrentTorrent, error) {
+	torrentData, ok := row.([]interface{})
+	if !ok {
+		return rtorrentTorrent{}, fmt.Errorf("got unexpected row %v", row)
+	}
+	if len(torrentData) != len(rtorrentTorrentFields) {
+		return rtorrentTorrent{}, fmt.Errorf("got %d values for %d fields", len(torrentData), len(rtorrentTorrentFields))
+	}
+	var err error
+	stringValues := make(map[int]string)
+	for _, i := range []int{0, 1, 2, 6, 7, 11} {
+		if stringValues[i], err = getRtorrentValue[string](torrentData, i); err != nil {
+			return rtorrentTorrent{}, fmt.Errorf("could not parse %s: %w", rtorrentTorrentFields[i], err)
+		}
+	}
+	intValues := make(map[int]int)
+	for _, i := range []int{3, 4, 5, 8, 9, 10} {
+		if intValues[i], err = getRtorrentValue[int](torrentData, i); err != nil {
+			return rtorrentTorrent{}, fmt.Errorf("could not parse %s: %w", rtorrentTorrentFields[i], err)
+		}
+	}
+	torrent := rtorrentTorrent{
+		hash:          stringValues[0],
+		name:          stringValues[1],
+		directory:     stringValues[2],
+		completed:     intValues[3] > 0,
+		ratio:         float64(intValues[4]) / 1000,
+		trackerStatus: stringValues[6],
+		started:       intValues[8] == 1,
+		active:        intValues[9] == 1,
+		hashing:       intValues[10] != 0,
+	}
+	if torrent.hash == "" {
+		return rtorrentTorrent{}, fmt.Errorf("got empty hash")
+	}
+	// ruTorrent stores the url encoded label in custom1
+	if label, err := url.QueryUnescape(stringValues[11]); err == nil {
+		torrent.label = strings.TrimSpace(label)
+	}
+	if finished := intValues[5]; finished > 0 {
+		torrent.finished = time.Unix(int64(finished), 0)
+	}
+	if seedingSince, err := strconv.ParseInt(strings.TrimSpace(stringValues[7]), 10, 64); err == nil && seedingSince > 0 {
+		torrent.seedingSince = time.Unix(seedingSince, 0)
+	} else {
+		torrent.seedingSince = torrent.finished
+	}
+	return torrent, nil
+}
+
+// getFilesAndTrackers requests the files and trackers of the given torrents with one system.multicall per batch of
+// torrents instead of two calls per torrent. Torrents removed since getTorrents are missing in the returned maps. Any
+// other fault or malformed row fails the whole retrieval, since a missing torrent would leave its media unlinked.
+func (retriever *RtorrentRetriever) getFilesAndTrackers(ctx context.Context, torrents []rtorrentTorrent) (map[string][]*domain.TorrentFile, map[string][]string, error) {
+	torrentFiles := make(map[string][]*domain.TorrentFile, len(torrents))
+	torrentTrackers := make(map[string][]string, len(torrents))
+	for batchStart := 0; batchStart < len(torrents); batchStart += rtorrentMulticallBatchSize {
+		batch := torrents[batchStart:min(batchStart+rtorrentMulticallBatchSize, len(torrents))]
+		calls := make([]interface{}, 0, 2*len(batch))
+		for _, torrent := range batch {
+			calls = append(calls,
+				map[string]interface{}{"methodName": "f.multicall", "params": []interface{}{torrent.hash, "", "f.path=", "f.size_bytes="}},
+				map[string]interface{}{"methodName": "t.multicall", "params": []interface{}{torrent.hash, "", "t.url="}},
+			)
+		}
+		results, err := retriever.xmlrpcClient.Call(ctx, "system.multicall", calls)
 		if err != nil {
-			return nil, fmt.Errorf("could not get torrent files from rtorrent: %w", err)
+			return nil, nil, fmt.Errorf("system.multicall XMLRPC call failed: %w", err)
 		}
-		for _, torrentFile := range torrentFiles {
-			torrentEntry.Files = append(torrentEntry.Files, &domain.TorrentFile{
-				Path: torrentFile.Path,
-				Size: int64(torrentFile.Size),
-			})
+		callResults, err := getRtorrentMulticallRows(results)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse system.multicall result: %w", err)
 		}
-		torrentEntries = append(torrentEntries, torrentEntry)
-		torrentTrackers, err := retriever.client.GetTrackers(context.Background(), torrent)
+		if len(callResults) != len(calls) {
+			return nil, nil, fmt.Errorf("got %d system.multicall results for %d calls", len(callResults), len(calls))
+		}
+		for i, torrent := range batch {
+			fileRows, err := getRtorrentMulticallRows(callResults[2*i])
+			if err != nil && isRtorrentTorrentNotFoundError(err) {
+				slog.Debug("Torrent has been removed from rtorrent in the meantime.", "hash", torrent.hash)
+				continue
+			} else if err != nil {
+				return nil, nil, fmt.Errorf("could not get files of torrent %q: %w", torrent.hash, err)
+			}
+			trackerRows, err := getRtorrentMulticallRows(callResults[2*i+1])
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not get trackers of torrent %q: %w", torrent.hash, err)
+			}
+			if torrentFiles[torrent.hash], err = parseRtorrentFiles(fileRows); err != nil {
+				return nil, nil, fmt.Errorf("could not parse files of torrent %q: %w", torrent.hash, err)
+			}
+			if torrentTrackers[torrent.hash], err = parseRtorrentTrackers(trackerRows); err != nil {
+				return nil, nil, fmt.Errorf("could not parse trackers of torrent %q: %w", torrent.hash, err)
+			}
+		}
+	}
+	return torrentFiles, torrentTrackers, nil
+}
+
+// parseRtorrentFiles parses the rows of an f.multicall call requesting f.path and f.size_bytes.
+func parseRtorrentFiles(fileRows []interface{}) ([]*domain.TorrentFile, error) {
+	files := make([]*domain.TorrentFile, 0, len(fileRows))
+	for _, fileRow := range fileRows {
+		fileData, ok := fileRow.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("got unexpected file row %v", fileRow)
+		}
+		path, err := getRtorrentValue[string](fileData, 0)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse file path: %w", err)
+		}
+		size, err := getRtorrentValue[int](fileData, 1)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse file size: %w", err)
+		}
+		files = append(files, &domain.TorrentFile{Path: path, Size: int64(size)})
+	}
+	return files, nil
+}
+
+// parseRtorrentTrackers parses the rows of a t.multicall call requesting t.url.
+func parseRtorrentTrackers(trackerRows []interface{}) ([]string, error) {
+	trackers := make([]string, 0, len(trackerRows))
+	for _, trackerRow := range trackerRows {
+		trackerData, ok := trackerRow.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("got unexpected tracker row %v", trackerRow)
+		}
+		if len(trackerData) == 0 {
+			continue
+		}
+		tracker, err := getRtorrentValue[string](trackerData, 0)
 		if err != nil {
-			return nil, fmt.Errorf("could not get trackerresolver from rtorrent: %w", err)
+			return nil, fmt.Errorf("could not parse tracker url: %w", err)
 		}
-		for _, tracker := range torrentTrackers {
-			if !slices.Contains(torrentEntry.Trackers, tracker) {
-				torrentEntry.Trackers = append(torrentEntry.Trackers, tracker)
+		trackers = append(trackers, tracker)
+	}
+	return trackers, nil
+}
+
+// getRtorrentMulticallRows returns the rows of a single system.multicall result which is either wrapped in an array
+// or a fault struct.
+func getRtorrentMulticallRows(callResult interface{}) ([]interface{}, error) {
+	if fault, ok := callResult.(map[string]interface{}); ok {
+		return nil, fmt.Errorf("call failed: %v", fault["faultString"])
+	}
+	wrappedResult, ok := callResult.([]interface{})
+	if !ok || len(wrappedResult) != 1 {
+		return nil, fmt.Errorf("got unexpected call result %v", callResult)
+	}
+	rows, ok := wrappedResult[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("got unexpected call result %v", callResult)
+	}
+	return rows, nil
+}
+
+// GetTorrentEntries requests all torrents in 1 + ceil(n / rtorrentMulticallBatchSize) round trips.
+func (retriever *RtorrentRetriever) GetTorrentEntries(ctx context.Context) ([]*domain.TorrentEntry, error) {
+	torrents, err := retriever.getTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get torrent list from rtorrent: %w", err)
+	}
+	torrentFiles, torrentTrackers, err := retriever.getFilesAndTrackers(ctx, torrents)
+	if err != nil {
+		return nil, fmt.Errorf("could not get torrent files and trackers from rtorrent: %w", err)
+	}
+	currentTime := time.Now()
+	torrentEntries := make([]*domain.TorrentEntry, 0, len(torrents))
+	for _, torrent := range torrents {
+		// the torrent has been removed in the meantime
+		files, ok := torrentFiles[torrent.hash]
+		if !ok {
+			continue
+		}
+		torrentEntry := &domain.TorrentEntry{
+			Client:               retriever.Name(),
+			Id:                   torrent.hash,
+			Name:                 torrent.name,
+			Files:                files,
+			Trackers:             []string{},
+			TrackerStatus:        torrent.trackerStatus,
+			Labels:               []string{},
+			Ratio:                torrent.ratio,
+			State:                getRtorrentTorrentState(torrent),
+			SeedingTime:          -1,
+			SeedingTimeEstimated: true,
+			// d.directory already contains the torrent name for multi file torrents just like f.path expects it
+			DownloadDir: torrent.directory,
+		}
+		if torrent.completed {
+			torrentEntry.Added = torrent.finished
+		}
+		if torrent.label != "" {
+			torrentEntry.Labels = append(torrentEntry.Labels, torrent.label)
+		}
+		if !torrent.seedingSince.IsZero() {
+			torrentEntry.SeedingTime = currentTime.Sub(torrent.seedingSince)
+		}
+		for _, tracker := range torrentTrackers[torrent.hash] {
+			if trackerUrl := domain.NormalizeTrackerUrl(tracker); !slices.Contains(torrentEntry.Trackers, trackerUrl) {
+				torrentEntry.Trackers = append(torrentEntry.Trackers, trackerUrl)
 			}
 		}
+		torrentEntries = append(torrentEntries, torrentEntry)
 	}
 	return torrentEntries, nil
 }
 
-func (retriever *RtorrentRetriever) DeleteTorrent(id string) error {
+// getRtorrentTorrentState derives the state from the flags of rtorrent which does not know queued or errored torrents.
+// Started but inactive torrents are paused in ruTorrent terms.
+func getRtorrentTorrentState(torrent rtorrentTorrent) domain.TorrentState {
+	switch {
+	case torrent.hashing:
+		return domain.TorrentStateChecking
+	case !torrent.started || !torrent.active:
+		return domain.TorrentStatePaused
+	case torrent.completed:
+		return domain.TorrentStateSeeding
+	default:
+		return domain.TorrentStateDownloading
+	}
+}
+
+func (retriever *RtorrentRetriever) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	switch mode {
+	case domain.RemovalModeRemoveWithData, domain.RemovalModeRemoveKeepData, domain.RemovalModePause, domain.RemovalModeRelabel:
+		return true
+	default:
+		return false
+	}
+}
+
+func (retriever *RtorrentRetriever) DeleteTorrent(ctx context.Context, id string, mode domain.RemovalMode) error {
 	hash := id
 	if retriever.dryRun {
-		slog.Info("[DRY RUN] Skipping rtorrent torrent deletion.", "hash", hash)
+		slog.Info("[DRY RUN] Skipping rtorrent torrent deletion.", "hash", hash, "mode", mode)
 		return nil
 	}
 	torrent := rtorrent.Torrent{Hash: hash}
-	if err := retriever.client.SetForceDelete(context.Background(), torrent, true); err != nil {
-		errString := err.Error()
-		if strings.Contains(errString, "Could not find info-hash") || strings.Contains(errString, "info-hash not found") {
-			return domain.ErrTorrentNotFound
+	var err error
+	switch mode {
+	case domain.RemovalModeRemoveWithData:
+		err = retriever.deleteTorrentWithData(ctx, torrent)
+	case domain.RemovalModeRemoveKeepData:
+		if err = retriever.client.Delete(ctx, torrent); err != nil {
+			err = fmt.Errorf("could not remove torrent %q: %w", hash, err)
 		}
-		return fmt.Errorf("could not force deletion for torrent %q: %w", hash, err)
+	case domain.RemovalModePause:
+		// stopped torrents are closed and reported as paused
+		if err = retriever.client.StopTorrent(ctx, torrent); err != nil {
+			err = fmt.Errorf("could not stop torrent %q: %w", hash, err)
+		}
+	case domain.RemovalModeRelabel:
+		// ruTorrent expects the label to be url encoded with %20 for spaces like in d.custom1 of getTorrents
+		label := strings.ReplaceAll(url.QueryEscape(retriever.seedOnlyLabel), "+", "%20")
+		if _, err = retriever.xmlrpcClient.Call(ctx, "d.custom1.set", hash, label); err != nil {
+			err = fmt.Errorf("could not set label of torrent %q: %w", hash, err)
+		}
+	default:
+		return domain.ErrRemovalModeNotSupported
 	}
-	if err := retriever.client.DeleteTied(context.Background(), torrent); err != nil {
-		return fmt.Errorf("could not delete tied files for torrent %q: %w", hash, err)
+	if err != nil && isRtorrentTorrentNotFoundError(err) {
+		return domain.ErrTorrentNotFound
 	}
-	if err := retriever.client.Delete(context.Background(), torrent); err != nil {
-		return fmt.Errorf("could not delete files for torrent %q: %w", hash, err)
+	return err
+}
+
+// deleteTorrentWithData flags the torrent for the data removal by ruTorrent's erasedata plugin before erasing it.
+func (retriever *RtorrentRetriever) deleteTorrentWithData(ctx context.Context, torrent rtorrent.Torrent) error {
+	if err := retriever.client.SetForceDelete(ctx, torrent, true); err != nil {
+		return fmt.Errorf("could not force deletion for torrent %q: %w", torrent.Hash, err)
+	}
+	if err := retriever.client.DeleteTied(ctx, torrent); err != nil {
+		return fmt.Errorf("could not delete tied files for torrent %q: %w", torrent.Hash, err)
+	}
+	if err := retriever.client.Delete(ctx, torrent); err != nil {
+		return fmt.Errorf("could not delete files for torrent %q: %w", torrent.Hash, err)
 	}
 	return nil
 }
 
+func isRtorrentTorrentNotFoundError(err error) bool {
+	errString := err.Error()
+	return strings.Contains(errString, "Could not find info-hash") || strings.Contains(errString, "info-hash not found")
+}
+
 func (retriever *RtorrentRetriever) Name() string {
-	return "rtorrent"
+	return retriever.name
 }
@@ -0,0 +1,236 @@
+package torrentclients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/autobrr/go-rtorrent"
+	"github.com/autobrr/go-rtorrent/xmlrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRtorrentServer serves the xmlrpc calls used to retrieve torrents for a fixed number of generated torrents.
+type fakeRtorrentServer struct {
+	torrentCount  int
+	filesCount    int
+	requestsCount atomic.Int64
+	// torrentCalls are the methods called for single torrents, e.g. to delete them
+	torrentCalls []string
+	// label is the last label set using d.custom1.set
+	label string
+	// malformedTorrent breaks the ratio of the second torrent
+	malformedTorrent bool
+	// malformedFiles breaks the file sizes of the third torrent
+	malformedFiles bool
+	// removedTorrent removes the fourth torrent after it has been listed by d.multicall2
+	removedTorrent bool
+}
+
+func (server *fakeRtorrentServer) getHash(i int) string {
+	return fmt.Sprintf("%040X", i)
+}
+
+func (server *fakeRtorrentServer) call(method string, params []interface{}) interface{} {
+	switch method {
+	case "d.multicall2":
+		rows := make([]interface{}, 0, server.torrentCount)
+		for i := 0; i < server.torrentCount; i++ {
+			row := []interface{}{server.getHash(i), fmt.Sprintf("Torrent %d", i), fmt.Sprintf("/downloads/Torrent %d", i),
+				1, 1500, 1767225600, "", "", 1, 1, 0, "Movies%20HD"}
+			if server.malformedTorrent && i == 1 {
+				row[4] = "1.5"
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	case "f.multicall", "t.multicall":
+		if server.removedTorrent && params[0] == server.getHash(3) {
+			return xmlrpc.Fault{Code: -501, Message: "Could not find info-hash."}
+		}
+		if method == "t.multicall" {
+			return []interface{}{[]interface{}{"https://tracker.example/0123456789abcdef0123456789abcdef/announce"}}
+		}
+		rows := make([]interface{}, 0, server.filesCount)
+		for i := 0; i < server.filesCount; i++ {
+			if server.malformedFiles && params[0] == server.getHash(2) {
+				rows = append(rows, []interface{}{fmt.Sprintf("file-%d.mkv", i)})
+				continue
+			}
+			rows = append(rows, []interface{}{fmt.Sprintf("file-%d.mkv", i), 1000})
+		}
+		return rows
+	case "d.custom1.set", "d.custom5.set", "d.delete_tied", "d.erase", "d.close", "d.stop":
+		if params[0] != server.getHash(0) {
+			return xmlrpc.Fault{Code: -501, Message: "Could not find info-hash."}
+		}
+		server.torrentCalls = append(server.torrentCalls, method)
+		if method == "d.custom1.set" {
+			server.label = params[1].(string)
+		}
+		return 0
+	case "system.multicall":
+		results := make([]interface{}, 0)
+		for _, call := range params[0].([]interface{}) {
+			call := call.(map[string]interface{})
+			result := server.call(call["methodName"].(string), call["params"].([]interface{}))
+			// faults of single calls are returned as structs instead of wrapped results
+			if fault, ok := result.(xmlrpc.Fault); ok {
+				results = append(results, map[string]interface{}{"faultCode": fault.Code, "faultString": fault.Message})
+				continue
+			}
+			results = append(results, []interface{}{result})
+		}
+		return results
+	default:
+		return xmlrpc.Fault{Code: -506, Message: fmt.Sprintf("method %q not defined", method)}
+	}
+}
+
+func (server *fakeRtorrentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.requestsCount.Add(1)
+	method, params, _, err := xmlrpc.Unmarshal(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	_ = xmlrpc.Marshal(w, "", server.call(method, params))
+}
+
+func newFakeRtorrentRetriever(t testing.TB, server *fakeRtorrentServer) *RtorrentRetriever {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return &RtorrentRetriever{
+		name:          "rtorrent",
+		client:        rtorrent.NewClient(rtorrent.Config{Addr: httpServer.URL}),
+		xmlrpcClient:  xmlrpc.NewClient(xmlrpc.Config{Addr: httpServer.URL}),
+		seedOnlyLabel: "seed only",
+	}
+}
+
+func TestRtorrentRetriever_GetTorrentEntries(t *testing.T) {
+	server := &fakeRtorrentServer{torrentCount: 2*rtorrentMulticallBatchSize + 1, filesCount: 2}
+	retriever := newFakeRtorrentRetriever(t, server)
+
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	// one d.multicall2 and three system.multicall batches
+	assert.Equal(t, int64(4), server.requestsCount.Load())
+	assert.Len(t, torrentEntries, server.torrentCount)
+	torrentEntry := torrentEntries[0]
+	torrentEntry.SeedingTime = 0
+	assert.Equal(t, &domain.TorrentEntry{
+		Client:               "rtorrent",
+		Id:                   server.getHash(0),
+		Name:                 "Torrent 0",
+		Ratio:                1.5,
+		State:                domain.TorrentStateSeeding,
+		Added:                time.Unix(1767225600, 0),
+		SeedingTimeEstimated: true,
+		Files: []*domain.TorrentFile{
+			{Path: "file-0.mkv", Size: 1000},
+			{Path: "file-1.mkv", Size: 1000},
+		},
+		Trackers:    []string{"https://tracker.example/REDACTED/announce"},
+		Labels:      []string{"Movies HD"},
+		DownloadDir: "/downloads/Torrent 0",
+	}, torrentEntry)
+}
+
+func TestRtorrentRetriever_GetTorrentEntries_malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  *fakeRtorrentServer
+		wantErr string
+	}{
+		{"malformed torrent", &fakeRtorrentServer{torrentCount: 4, filesCount: 1, malformedTorrent: true}, "could not parse d.ratio="},
+		{"malformed files", &fakeRtorrentServer{torrentCount: 4, filesCount: 1, malformedFiles: true}, "could not parse files of torrent"},
+		{"removed torrent and malformed files", &fakeRtorrentServer{torrentCount: 4, filesCount: 1, removedTorrent: true, malformedFiles: true}, "could not parse files of torrent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a missing torrent would leave its media unlinked, so the whole retrieval fails
+			torrentEntries, err := newFakeRtorrentRetriever(t, tt.server).GetTorrentEntries(context.Background())
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Nil(t, torrentEntries)
+		})
+	}
+}
+
+func TestRtorrentRetriever_GetTorrentEntries_removed(t *testing.T) {
+	server := &fakeRtorrentServer{torrentCount: 5, filesCount: 1, removedTorrent: true}
+	retriever := newFakeRtorrentRetriever(t, server)
+
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	// torrents removed after being listed are skipped
+	ids := make([]string, 0, len(torrentEntries))
+	for _, torrentEntry := range torrentEntries {
+		ids = append(ids, torrentEntry.Id)
+	}
+	assert.Equal(t, []string{server.getHash(0), server.getHash(1), server.getHash(2), server.getHash(4)}, ids)
+}
+
+func Test_parseRtorrentTorrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     interface{}
+		wantErr string
+	}{
+		{"no row", "not a row", "got unexpected row"},
+		{"missing fields", []interface{}{"0123"}, "got 1 values for 12 fields"},
+		{"wrong type", []interface{}{"0123", "Torrent", "/downloads", 1, "1.5", 0, "", "", 1, 1, 0, ""}, "could not parse d.ratio="},
+		{"empty hash", []interface{}{"", "Torrent", "/downloads", 1, 1500, 0, "", "", 1, 1, 0, ""}, "got empty hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRtorrentTorrent(tt.row)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRtorrentRetriever_DeleteTorrent(t *testing.T) {
+	tests := []struct {
+		mode      domain.RemovalMode
+		wantCalls []string
+	}{
+		{domain.RemovalModeRemoveWithData, []string{"d.custom5.set", "d.delete_tied", "d.erase"}},
+		{domain.RemovalModeRemoveKeepData, []string{"d.erase"}},
+		{domain.RemovalModePause, []string{"d.close", "d.stop"}},
+		{domain.RemovalModeRelabel, []string{"d.custom1.set"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			server := &fakeRtorrentServer{torrentCount: 1}
+			retriever := newFakeRtorrentRetriever(t, server)
+			assert.NoError(t, retriever.DeleteTorrent(context.Background(), server.getHash(0), tt.mode))
+			assert.Equal(t, tt.wantCalls, server.torrentCalls)
+			if tt.mode == domain.RemovalModeRelabel {
+				assert.Equal(t, "seed%20only", server.label)
+			}
+			assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), server.getHash(1), tt.mode), domain.ErrTorrentNotFound)
+		})
+	}
+}
+
+func BenchmarkRtorrentRetriever_GetTorrentEntries(b *testing.B) {
+	server := &fakeRtorrentServer{torrentCount: 4000, filesCount: 3}
+	retriever := newFakeRtorrentRetriever(b, server)
+	for b.Loop() {
+		if _, err := retriever.GetTorrentEntries(context.Background()); err != nil {
+			b.Fatalf("GetTorrentEntries() error = %v", err)
+		}
+	}
+	b.ReportMetric(float64(server.requestsCount.Load())/float64(b.N), "requests/op")
+}
@@ -0,0 +1,138 @@
+package torrentclients
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var _ http.RoundTripper = (*scgiTransport)(nil)
+
+// scgiTransport sends http requests over the simple common gateway interface (SCGI) as exposed by rtorrent via
+// network.scgi.open_port or network.scgi.open_local. Every request uses a new connection as SCGI does not support
+// keep-alive.
+type scgiTransport struct {
+	network string
+	address string
+	dialer  net.Dialer
+}
+
+// newScgiTransport returns a transport for scgi://host:port or unix:///path/to/socket urls.
+func newScgiTransport(rawUrl string) (*scgiTransport, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse scgi url %q: %w", rawUrl, err)
+	}
+	switch parsedUrl.Scheme {
+	case "scgi":
+		if parsedUrl.Host == "" {
+			return nil, fmt.Errorf("scgi url %q has no host", rawUrl)
+		}
+		return &scgiTransport{network: "tcp", address: parsedUrl.Host}, nil
+	case "unix":
+		if parsedUrl.Path == "" {
+			return nil, fmt.Errorf("unix url %q has no socket path", rawUrl)
+		}
+		return &scgiTransport{network: "unix", address: parsedUrl.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scgi url scheme %q", parsedUrl.Scheme)
+	}
+}
+
+func isScgiUrl(rawUrl string) bool {
+	return strings.HasPrefix(rawUrl, "scgi://") || strings.HasPrefix(rawUrl, "unix://")
+}
+
+func (transport *scgiTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+	}
+	conn, err := transport.dialer.DialContext(request.Context(), transport.network, transport.address)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to scgi endpoint: %w", err)
+	}
+	if deadline, ok := request.Context().Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err = conn.Write(getScgiRequest(request, body)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not send scgi request: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not read scgi response header: %w", err)
+	}
+	// the status line of http is passed as Status header and defaults to 200
+	statusCode := http.StatusOK
+	if status := header.Get("Status"); status != "" {
+		statusCode, err = strconv.Atoi(strings.SplitN(status, " ", 2)[0])
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("could not parse scgi response status %q: %w", status, err)
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.0",
+		ProtoMajor:    1,
+		Header:        http.Header(header),
+		Body:          scgiResponseBody{reader, conn},
+		ContentLength: -1,
+		Request:       request,
+	}, nil
+}
+
+// getScgiRequest encodes the request headers as netstring followed by the body.
+func getScgiRequest(request *http.Request, body []byte) []byte {
+	var headers bytes.Buffer
+	// CONTENT_LENGTH has to be the first header
+	for _, header := range [][2]string{
+		{"CONTENT_LENGTH", strconv.Itoa(len(body))},
+		{"SCGI", "1"},
+		{"REQUEST_METHOD", request.Method},
+		{"REQUEST_URI", request.URL.RequestURI()},
+		{"CONTENT_TYPE", request.Header.Get("Content-Type")},
+	} {
+		headers.WriteString(header[0])
+		headers.WriteByte(0)
+		headers.WriteString(header[1])
+		headers.WriteByte(0)
+	}
+	var scgiRequest bytes.Buffer
+	scgiRequest.WriteString(strconv.Itoa(headers.Len()))
+	scgiRequest.WriteByte(':')
+	scgiRequest.Write(headers.Bytes())
+	scgiRequest.WriteByte(',')
+	scgiRequest.Write(body)
+	return scgiRequest.Bytes()
+}
+
+// scgiResponseBody reads the remaining response and closes the connection afterward.
+type scgiResponseBody struct {
+	io.Reader
+	conn net.Conn
+}
+
+func (body scgiResponseBody) Close() error {
+	if err := body.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
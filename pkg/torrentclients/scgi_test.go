@@ -0,0 +1,128 @@
+package torrentclients
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-rtorrent/xmlrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveScgi answers SCGI requests on the listener with the given handler like rtorrent does.
+func serveScgi(listener net.Listener, handler http.Handler) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			headersLengthRaw, err := reader.ReadString(':')
+			if err != nil {
+				return
+			}
+			headersLength, _ := strconv.Atoi(strings.TrimSuffix(headersLengthRaw, ":"))
+			headersRaw := make([]byte, headersLength+1)
+			if _, err = io.ReadFull(reader, headersRaw); err != nil {
+				return
+			}
+			headerValues := strings.Split(string(headersRaw[:headersLength]), "\x00")
+			headers := make(map[string]string)
+			for i := 0; i+1 < len(headerValues); i += 2 {
+				headers[headerValues[i]] = headerValues[i+1]
+			}
+			contentLength, _ := strconv.Atoi(headers["CONTENT_LENGTH"])
+			body := make([]byte, contentLength)
+			if _, err = io.ReadFull(reader, body); err != nil {
+				return
+			}
+			request := httptest.NewRequest(headers["REQUEST_METHOD"], headers["REQUEST_URI"], bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			_, _ = fmt.Fprintf(conn, "Status: %d OK\r\nContent-Type: text/xml\r\n\r\n", recorder.Code)
+			_, _ = conn.Write(recorder.Body.Bytes())
+		}()
+	}
+}
+
+func TestScgiTransport(t *testing.T) {
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen on tcp: %v", err)
+	}
+	unixSocketPath := filepath.Join(t.TempDir(), "rtorrent.sock")
+	unixListener, err := net.Listen("unix", unixSocketPath)
+	if err != nil {
+		t.Fatalf("could not listen on unix socket: %v", err)
+	}
+	for _, listener := range []net.Listener{tcpListener, unixListener} {
+		t.Cleanup(func() { _ = listener.Close() })
+		go serveScgi(listener, &fakeRtorrentServer{torrentCount: 3, filesCount: 2})
+	}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"scgi over tcp", "scgi://" + tcpListener.Addr().String()},
+		{"scgi over unix socket", "unix://" + unixSocketPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newScgiTransport(tt.url)
+			if err != nil {
+				t.Fatalf("newScgiTransport() error = %v", err)
+			}
+			retriever := &RtorrentRetriever{
+				name: "rtorrent",
+				xmlrpcClient: xmlrpc.NewClient(xmlrpc.Config{
+					Addr:   tt.url,
+					Client: &http.Client{Transport: transport},
+				}),
+			}
+			torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+			if err != nil {
+				t.Fatalf("GetTorrentEntries() error = %v", err)
+			}
+			assert.Len(t, torrentEntries, 3)
+			assert.Len(t, torrentEntries[2].Files, 2)
+		})
+	}
+}
+
+func TestNewScgiTransport(t *testing.T) {
+	tests := []struct {
+		url         string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{"scgi://localhost:5000", "tcp", "localhost:5000", false},
+		{"unix:///home/user/.rtorrent.sock", "unix", "/home/user/.rtorrent.sock", false},
+		{"scgi:///no/host", "", "", true},
+		{"https://somedomain.com/RPC2", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			transport, err := newScgiTransport(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNetwork, transport.network)
+			assert.Equal(t, tt.wantAddress, transport.address)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package torrentclients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+)
+
+var _ domain.TorrentSource = (*TransmissionRetriever)(nil)
+
+const transmissionSessionIdHeader = "X-Transmission-Session-Id"
+
+type TransmissionRetriever struct {
+	name      string
+	client    *http.Client
+	rpcUrl    string
+	username  string
+	password  string
+	dryRun    bool
+	sessionId string
+	// sessionLock guards sessionId which is renewed by the rpc server at any time
+	sessionLock *sync.Mutex
+}
+
+type transmissionRequest struct {
+	Method    string `json:"method"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+type transmissionResponse struct {
+	Result    string          `json:"result"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type transmissionTorrent struct {
+	HashString     string   `json:"hashString"`
+	Name           string   `json:"name"`
+	UploadRatio    float64  `json:"uploadRatio"`
+	DoneDate       int64    `json:"doneDate"`
+	DownloadDir    string   `json:"downloadDir"`
+	SecondsSeeding int64    `json:"secondsSeeding"`
+	Status         int      `json:"status"`
+	Error          int      `json:"error"`
+	Labels         []string `json:"labels"`
+	Files          []struct {
+		Name   string `json:"name"`
+		Length int64  `json:"length"`
+	} `json:"files"`
+	Trackers []struct {
+		Announce string `json:"announce"`
+	} `json:"trackers"`
+}
+
+var transmissionTorrentFields = []string{"hashString", "name", "uploadRatio", "doneDate", "downloadDir", "secondsSeeding", "status", "error", "labels", "files", "trackers"}
+
+func NewTransmissionRetriever(name string, rpcUrl string, username string, password string, timeout time.Duration, dryRun bool) (*TransmissionRetriever, error) {
+	retriever := &TransmissionRetriever{
+		name:        name,
+		client:      &http.Client{Timeout: timeout},
+		rpcUrl:      rpcUrl,
+		username:    username,
+		password:    password,
+		dryRun:      dryRun,
+		sessionLock: &sync.Mutex{},
+	}
+	if err := retriever.call(context.Background(), "session-get", nil, nil); err != nil {
+		return nil, fmt.Errorf("could not connect to remote transmission rpc api: %w", err)
+	}
+	return retriever, nil
+}
+
+func (retriever *TransmissionRetriever) GetTorrentEntries(ctx context.Context) ([]*domain.TorrentEntry, error) {
+	torrentList, err := retriever.getTorrents(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get torrent list from transmission rpc api: %w", err)
+	}
+	torrentEntries := make([]*domain.TorrentEntry, 0, len(torrentList))
+	for _, torrent := range torrentList {
+		torrentEntry := &domain.TorrentEntry{
+			Client:      retriever.Name(),
+			Id:          torrent.HashString,
+			Name:        torrent.Name,
+			Files:       make([]*domain.TorrentFile, 0, len(torrent.Files)),
+			Trackers:    []string{},
+			Labels:      []string{},
+			Ratio:       torrent.UploadRatio,
+			State:       getTransmissionTorrentState(torrent),
+			SeedingTime: time.Duration(torrent.SecondsSeeding) * time.Second,
+			DownloadDir: torrent.DownloadDir,
+		}
+		// doneDate is 0 for torrents that have not been completed yet
+		if torrent.DoneDate > 0 {
+			torrentEntry.Added = time.Unix(torrent.DoneDate, 0).In(time.UTC)
+		}
+		// labels are supported since transmission 3.0
+		for _, label := range torrent.Labels {
+			if label != "" && !slices.Contains(torrentEntry.Labels, label) {
+				torrentEntry.Labels = append(torrentEntry.Labels, label)
+			}
+		}
+		for _, file := range torrent.Files {
+			torrentEntry.Files = append(torrentEntry.Files, &domain.TorrentFile{
+				Path: file.Name,
+				Size: file.Length,
+			})
+		}
+		for _, tracker := range torrent.Trackers {
+			if trackerUrl := domain.NormalizeTrackerUrl(tracker.Announce); !slices.Contains(torrentEntry.Trackers, trackerUrl) {
+				torrentEntry.Trackers = append(torrentEntry.Trackers, trackerUrl)
+			}
+		}
+		torrentEntries = append(torrentEntries, torrentEntry)
+	}
+	return torrentEntries, nil
+}
+
+// getTransmissionTorrentState maps the numeric status of the rpc api. A non-zero error marks tracker or local errors.
+func getTransmissionTorrentState(torrent transmissionTorrent) domain.TorrentState {
+	// errors 1 and 2 are tracker warnings and errors which do not affect the local data
+	if torrent.Error == 3 {
+		return domain.TorrentStateError
+	}
+	switch torrent.Status {
+	case 0:
+		return domain.TorrentStatePaused
+	case 1, 2:
+		return domain.TorrentStateChecking
+	case 3, 5:
+		return domain.TorrentStateQueued
+	case 4:
+		return domain.TorrentStateDownloading
+	case 6:
+		return domain.TorrentStateSeeding
+	default:
+		return domain.TorrentStateUnknown
+	}
+}
+
+// SupportsRemovalMode only reports domain.RemovalModeRemoveWithData as supported as the other modes are not implemented
+// for this client.
+func (retriever *TransmissionRetriever) SupportsRemovalMode(mode domain.RemovalMode) bool {
+	return mode == domain.RemovalModeRemoveWithData
+}
+
+func (retriever *TransmissionRetriever) DeleteTorrent(ctx context.Context, id string, mode domain.RemovalMode) error {
+	if !retriever.SupportsRemovalMode(mode) {
+		return domain.ErrRemovalModeNotSupported
+	}
+	hash := strings.ToLower(id)
+	torrentList, err := retriever.getTorrents(ctx, []string{hash})
+	if err != nil {
+		return fmt.Errorf("could not check torrent %q on transmission rpc api: %w", hash, err)
+	}
+	if len(torrentList) == 0 {
+		return domain.ErrTorrentNotFound
+	}
+	if retriever.dryRun {
+		slog.Info("[DRY RUN] Skipping transmission torrent deletion.", "hash", hash)
+		return nil
+	}
+	arguments := map[string]any{
+		"ids":               []string{hash},
+		"delete-local-data": true,
+	}
+	if err = retriever.call(ctx, "torrent-remove", arguments, nil); err != nil {
+		return fmt.Errorf("could not remove torrent %q from transmission rpc api: %w", hash, err)
+	}
+	return nil
+}
+
+func (retriever *TransmissionRetriever) Name() string {
+	return retriever.name
+}
+
+func (retriever *TransmissionRetriever) getTorrents(ctx context.Context, ids []string) ([]transmissionTorrent, error) {
+	arguments := map[string]any{"fields": transmissionTorrentFields}
+	if ids != nil {
+		arguments["ids"] = ids
+	}
+	var result struct {
+		Torrents []transmissionTorrent `json:"torrents"`
+	}
+	if err := retriever.call(ctx, "torrent-get", arguments, &result); err != nil {
+		return nil, err
+	}
+	return result.Torrents, nil
+}
+
+// call executes the given rpc method and decodes the response arguments into receivingValue if it is not nil. The
+// session id handshake (HTTP 409) is handled transparently.
+func (retriever *TransmissionRetriever) call(ctx context.Context, method string, arguments any, receivingValue any) error {
+	payload, err := json.Marshal(transmissionRequest{Method: method, Arguments: arguments})
+	if err != nil {
+		return fmt.Errorf("could not encode %q request: %w", method, err)
+	}
+	resp, body, err := retriever.post(ctx, payload)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		retriever.sessionLock.Lock()
+		retriever.sessionId = resp.Header.Get(transmissionSessionIdHeader)
+		retriever.sessionLock.Unlock()
+		resp, body, err = retriever.post(ctx, payload)
+		if err != nil {
+			return err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response to %q (status code: %d): %q", method, resp.StatusCode, string(body))
+	}
+	var response transmissionResponse
+	if err = json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("could not decode %q response: %w", method, err)
+	}
+	if response.Result != "success" {
+		return fmt.Errorf("%q failed: %s", method, response.Result)
+	}
+	if receivingValue == nil {
+		return nil
+	}
+	if err = json.Unmarshal(response.Arguments, receivingValue); err != nil {
+		return fmt.Errorf("could not decode %q response arguments: %w", method, err)
+	}
+	return nil
+}
+
+func (retriever *TransmissionRetriever) post(ctx context.Context, payload []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, retriever.rpcUrl, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	retriever.sessionLock.Lock()
+	req.Header.Set(transmissionSessionIdHeader, retriever.sessionId)
+	retriever.sessionLock.Unlock()
+	if retriever.username != "" || retriever.password != "" {
+		req.SetBasicAuth(retriever.username, retriever.password)
+	}
+	resp, err := retriever.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read response: %w", err)
+	}
+	return resp, body, nil
+}
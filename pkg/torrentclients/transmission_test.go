@@ -0,0 +1,144 @@
+package torrentclients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTransmissionServer implements the rpc api of transmission for a fixed list of torrents including the session id
+// handshake.
+type fakeTransmissionServer struct {
+	sessionId string
+	// conflicts is the number of requests rejected with 409 due to a missing or outdated session id
+	conflicts int
+	torrents  []map[string]any
+	// removeArguments are the arguments of the last torrent-remove call
+	removeArguments map[string]any
+}
+
+func (server *fakeTransmissionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, _ := r.BasicAuth(); username != "admin" || password != "secret" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get(transmissionSessionIdHeader) != server.sessionId {
+		server.conflicts++
+		w.Header().Set(transmissionSessionIdHeader, server.sessionId)
+		http.Error(w, "Conflict", http.StatusConflict)
+		return
+	}
+	var request struct {
+		Method    string         `json:"method"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := "success"
+	arguments := map[string]any{}
+	switch request.Method {
+	case "session-get":
+		arguments["version"] = "4.0.6"
+	case "torrent-get":
+		torrents := make([]map[string]any, 0)
+		for _, torrent := range server.torrents {
+			ids, _ := request.Arguments["ids"].([]any)
+			if ids == nil || slices.Contains(ids, torrent["hashString"]) {
+				torrents = append(torrents, torrent)
+			}
+		}
+		arguments["torrents"] = torrents
+	case "torrent-remove":
+		server.removeArguments = request.Arguments
+		server.torrents = slices.DeleteFunc(server.torrents, func(torrent map[string]any) bool {
+			return slices.Contains(request.Arguments["ids"].([]any), torrent["hashString"])
+		})
+	default:
+		result = fmt.Sprintf("method name not recognized: %s", request.Method)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "arguments": arguments})
+}
+
+func TestTransmissionRetriever(t *testing.T) {
+	server := &fakeTransmissionServer{sessionId: "session-1", torrents: []map[string]any{{
+		"hashString":     "0123456789abcdef0123456789abcdef01234567",
+		"name":           "Some Movie",
+		"uploadRatio":    2.5,
+		"doneDate":       1767225600,
+		"downloadDir":    "/downloads",
+		"secondsSeeding": 7200,
+		"status":         6,
+		"error":          0,
+		"labels":         []string{"movies", "", "movies"},
+		"files":          []map[string]any{{"name": "Some Movie/movie.mkv", "length": 1000}},
+		"trackers":       []map[string]any{{"announce": "https://tracker.example/announce?passkey=secret"}},
+	}, {
+		"hashString": "89abcdef0123456789abcdef0123456789abcdef",
+		"name":       "Incomplete Movie",
+		"doneDate":   0,
+		"status":     4,
+		"error":      3,
+	}}}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	_, err := NewTransmissionRetriever("transmission", httpServer.URL+"/transmission/rpc", "admin", "wrong", time.Minute, false)
+	assert.ErrorContains(t, err, "status code: 401")
+
+	retriever, err := NewTransmissionRetriever("transmission", httpServer.URL+"/transmission/rpc", "admin", "secret", time.Minute, false)
+	if err != nil {
+		t.Fatalf("NewTransmissionRetriever() error = %v", err)
+	}
+	// the first request is sent without session id and retried with the one of the 409 response
+	assert.Equal(t, 1, server.conflicts)
+
+	// the session id changed in the meantime
+	server.sessionId = "session-2"
+	torrentEntries, err := retriever.GetTorrentEntries(context.Background())
+	if err != nil {
+		t.Fatalf("GetTorrentEntries() error = %v", err)
+	}
+	assert.Equal(t, 2, server.conflicts)
+	assert.Equal(t, []*domain.TorrentEntry{{
+		Client:      "transmission",
+		Id:          "0123456789abcdef0123456789abcdef01234567",
+		Name:        "Some Movie",
+		State:       domain.TorrentStateSeeding,
+		Files:       []*domain.TorrentFile{{Path: "Some Movie/movie.mkv", Size: 1000}},
+		Trackers:    []string{"https://tracker.example/announce?passkey=REDACTED"},
+		Labels:      []string{"movies"},
+		Ratio:       2.5,
+		SeedingTime: 2 * time.Hour,
+		Added:       time.Unix(1767225600, 0).In(time.UTC),
+		DownloadDir: "/downloads",
+	}, {
+		Client: "transmission",
+		Id:     "89abcdef0123456789abcdef0123456789abcdef",
+		Name:   "Incomplete Movie",
+		// local errors take precedence over the status
+		State:    domain.TorrentStateError,
+		Files:    []*domain.TorrentFile{},
+		Trackers: []string{},
+		Labels:   []string{},
+	}}, torrentEntries)
+
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "0123456789ABCDEF0123456789ABCDEF01234567", domain.RemovalModeRemoveKeepData), domain.ErrRemovalModeNotSupported)
+	assert.Nil(t, server.removeArguments)
+	assert.NoError(t, retriever.DeleteTorrent(context.Background(), "0123456789ABCDEF0123456789ABCDEF01234567", domain.RemovalModeRemoveWithData))
+	assert.Equal(t, map[string]any{
+		"ids":               []any{"0123456789abcdef0123456789abcdef01234567"},
+		"delete-local-data": true,
+	}, server.removeArguments)
+	assert.ErrorIs(t, retriever.DeleteTorrent(context.Background(), "0123456789abcdef0123456789abcdef01234567", domain.RemovalModeRemoveWithData), domain.ErrTorrentNotFound)
+}
@@ -3,6 +3,7 @@ package trackerresolver
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/almanac1631/scrubarr/pkg/domain"
@@ -19,50 +20,96 @@ type TrackerConfig struct {
 
 type Service struct {
 	trackerConfigs []TrackerConfig
+	// labelConfigs are matched against the labels of torrents and take precedence over the tracker configs
+	labelConfigs []TrackerConfig
 }
 
-func NewService(trackerConfigs []TrackerConfig) *Service {
+func NewService(trackerConfigs []TrackerConfig, labelConfigs []TrackerConfig) *Service {
 	return &Service{
 		trackerConfigs: trackerConfigs,
+		labelConfigs:   labelConfigs,
 	}
 }
 
 func NewServiceFromKoanf(config *koanf.Koanf) (*Service, error) {
-	manager := &Service{
-		trackerConfigs: make([]TrackerConfig, 0),
+	trackerConfigs, err := getTrackerConfigs(config, "trackers")
+	if err != nil {
+		return nil, err
 	}
-	for _, trackerKey := range config.MapKeys("trackers") {
-		name := config.MustString(fmt.Sprintf("trackers.%s.name", trackerKey))
+	labelConfigs, err := getTrackerConfigs(config, "labels")
+	if err != nil {
+		return nil, err
+	}
+	return NewService(trackerConfigs, labelConfigs), nil
+}
+
+// getTrackerConfigs parses the rules of the given section. Rules with keep set do not require any thresholds.
+func getTrackerConfigs(config *koanf.Koanf, section string) ([]TrackerConfig, error) {
+	trackerConfigs := make([]TrackerConfig, 0)
+	for _, trackerKey := range config.MapKeys(section) {
+		prefix := fmt.Sprintf("%s.%s", section, trackerKey)
+		name := config.MustString(prefix + ".name")
+		keep := config.Bool(prefix + ".keep")
 		var minRatio float64
-		minRatio, err := getSetConfigValue[float64](config, fmt.Sprintf("trackers.%s.min_ratio", trackerKey))
-		if err != nil {
-			return nil, err
+		var err error
+		if !keep || config.Exists(prefix+".min_ratio") {
+			minRatio, err = getSetConfigValue[float64](config, prefix+".min_ratio")
+			if err != nil {
+				return nil, err
+			}
 		}
-		minAge, err := getSetConfigValue[time.Duration](config, fmt.Sprintf("trackers.%s.min_age", trackerKey))
-		if err != nil {
-			return nil, err
+		var minSeedTime time.Duration
+		if config.Exists(prefix + ".min_seed_time") {
+			minSeedTime, err = getSetConfigValue[time.Duration](config, prefix+".min_seed_time")
+			if err != nil {
+				return nil, err
+			}
+		}
+		// min_age may be omitted if the tracker only requires a seeding time
+		var minAge time.Duration
+		if (!keep && minSeedTime == 0) || config.Exists(prefix+".min_age") {
+			minAge, err = getSetConfigValue[time.Duration](config, prefix+".min_age")
+			if err != nil {
+				return nil, err
+			}
 		}
-		patternRaw := config.MustString(fmt.Sprintf("trackers.%s.pattern", trackerKey))
+		patternRaw := config.MustString(prefix + ".pattern")
 		pattern, err := regexp.Compile(patternRaw)
 		if err != nil {
-			return nil, fmt.Errorf("could not compile pattern for tracker %q: %w", trackerKey, err)
+			return nil, fmt.Errorf("could not compile pattern for %s %q: %w", strings.TrimSuffix(section, "s"), trackerKey, err)
 		}
-		manager.trackerConfigs = append(manager.trackerConfigs, TrackerConfig{
+		trackerConfigs = append(trackerConfigs, TrackerConfig{
 			Tracker: &domain.Tracker{
-				Name:     name,
-				MinRatio: minRatio,
-				MinAge:   minAge,
+				Name:        name,
+				MinRatio:    minRatio,
+				MinAge:      minAge,
+				MinSeedTime: minSeedTime,
+				Keep:        keep,
 			},
 			Pattern: pattern,
 		})
 	}
-	return manager, nil
+	return trackerConfigs, nil
 }
 
+// Resolve returns the first configured tracker whose pattern matches either the host or the normalized announce url of
+// one of the given trackers. This way, host patterns work for every client regardless of the announce urls it reports.
 func (c Service) Resolve(trackers []string) (*domain.Tracker, error) {
 	for _, config := range c.trackerConfigs {
 		for _, tracker := range trackers {
-			if config.Pattern.MatchString(tracker) {
+			if config.Pattern.MatchString(domain.GetTrackerHost(tracker)) || config.Pattern.MatchString(tracker) {
+				return config.Tracker, nil
+			}
+		}
+	}
+	return nil, retentionpolicy.ErrTrackerNotFound
+}
+
+// ResolveLabels returns the first configured label rule whose pattern matches one of the given labels.
+func (c Service) ResolveLabels(labels []string) (*domain.Tracker, error) {
+	for _, config := range c.labelConfigs {
+		for _, label := range labels {
+			if config.Pattern.MatchString(label) {
 				return config.Tracker, nil
 			}
 		}
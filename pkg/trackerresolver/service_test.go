@@ -0,0 +1,213 @@
+package trackerresolver
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/almanac1631/scrubarr/pkg/domain"
+	"github.com/almanac1631/scrubarr/pkg/retentionpolicy"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestConfig(t *testing.T, config string) *koanf.Koanf {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scrubarr.toml")
+	if err := os.WriteFile(path, []byte(config), 0666); err != nil {
+		t.Fatalf("could not write config: %v", err)
+	}
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		t.Fatalf("could not load config: %v", err)
+	}
+	return k
+}
+
+func Test_getTrackerConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		section string
+		config  string
+		want    []domain.Tracker
+		wantErr string
+	}{
+		{"all requirements", "trackers", `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_ratio = 1.5
+min_age = "720h"
+min_seed_time = "168h"`, []domain.Tracker{{Name: "My Tracker", MinRatio: 1.5, MinAge: 720 * time.Hour, MinSeedTime: 168 * time.Hour}}, ""},
+		{"seed time without age", "trackers", `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_ratio = 1.0
+min_seed_time = "168h"`, []domain.Tracker{{Name: "My Tracker", MinRatio: 1, MinSeedTime: 168 * time.Hour}}, ""},
+		{"missing ratio", "trackers", `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_age = "720h"`, nil, `no value for key "trackers.my_tracker.min_ratio" found`},
+		{"missing age and seed time", "trackers", `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_ratio = 1.0`, nil, `no value for key "trackers.my_tracker.min_age" found`},
+		{"invalid duration", "trackers", `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_ratio = 1.0
+min_age = "30d"`, nil, `could not parse duration "30d"`},
+		{"keep without requirements", "labels", `
+[labels.keep]
+name = "Keep"
+pattern = "^keep$"
+keep = true`, []domain.Tracker{{Name: "Keep", Keep: true}}, ""},
+		{"keep with optional requirements", "labels", `
+[labels.keep]
+name = "Keep"
+pattern = "^keep$"
+keep = true
+min_age = "24h"`, []domain.Tracker{{Name: "Keep", Keep: true, MinAge: 24 * time.Hour}}, ""},
+		{"rules in key order", "labels", `
+[labels.racing]
+name = "Racing"
+pattern = "^racing$"
+min_ratio = 3.0
+min_age = "336h"
+
+[labels.archive]
+name = "Archive"
+pattern = "^archive$"
+keep = true`, []domain.Tracker{{Name: "Archive", Keep: true}, {Name: "Racing", MinRatio: 3, MinAge: 336 * time.Hour}}, ""},
+		{"invalid pattern", "labels", `
+[labels.keep]
+name = "Keep"
+pattern = "^(keep$"
+keep = true`, nil, `could not compile pattern for label "keep"`},
+		{"missing section", "labels", ``, []domain.Tracker{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trackerConfigs, err := getTrackerConfigs(loadTestConfig(t, tt.config), tt.section)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("getTrackerConfigs() error = %v", err)
+			}
+			trackers := make([]domain.Tracker, 0, len(trackerConfigs))
+			for _, trackerConfig := range trackerConfigs {
+				trackers = append(trackers, *trackerConfig.Tracker)
+			}
+			assert.Equal(t, tt.want, trackers)
+		})
+	}
+}
+
+func TestNewServiceFromKoanf(t *testing.T) {
+	service, err := NewServiceFromKoanf(loadTestConfig(t, `
+[trackers.my_tracker]
+name = "My Tracker"
+pattern = "^tracker\\.example$"
+min_ratio = 1.0
+min_age = "720h"
+
+[labels.keep]
+name = "Keep"
+pattern = "^keep$"
+keep = true`))
+	if err != nil {
+		t.Fatalf("NewServiceFromKoanf() error = %v", err)
+	}
+	// tracker and label rules are resolved independently
+	tracker, err := service.Resolve([]string{"keep"})
+	assert.ErrorIs(t, err, retentionpolicy.ErrTrackerNotFound)
+	assert.Nil(t, tracker)
+	tracker, err = service.ResolveLabels([]string{"keep"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Keep", tracker.Name)
+	tracker, err = service.ResolveLabels([]string{"tracker.example"})
+	assert.ErrorIs(t, err, retentionpolicy.ErrTrackerNotFound)
+	assert.Nil(t, tracker)
+
+	_, err = NewServiceFromKoanf(loadTestConfig(t, `
+[labels.keep]
+name = "Keep"
+pattern = "^keep$"`))
+	assert.ErrorContains(t, err, `no value for key "labels.keep.min_ratio" found`)
+}
+
+func TestService_Resolve(t *testing.T) {
+	myTracker := &domain.Tracker{Name: "My Tracker"}
+	privateTracker := &domain.Tracker{Name: "Private Tracker"}
+	service := NewService([]TrackerConfig{
+		{myTracker, regexp.MustCompile(`^tracker\.example$`)},
+		{privateTracker, regexp.MustCompile(`/private/REDACTED/announce$`)},
+	}, nil)
+	tests := []struct {
+		name     string
+		trackers []string
+		want     *domain.Tracker
+	}{
+		{"host of announce url", []string{"https://tracker.example:443/announce?passkey=REDACTED"}, myTracker},
+		{"plain host", []string{"tracker.example"}, myTracker},
+		{"normalized announce url", []string{"https://other.example/private/REDACTED/announce"}, privateTracker},
+		{"first config wins over tracker order", []string{"https://other.example/private/REDACTED/announce", "https://tracker.example/announce"}, myTracker},
+		{"any tracker", []string{"https://unknown.example/announce", "https://tracker.example/announce"}, myTracker},
+		{"host is matched exactly", []string{"https://sub.tracker.example/announce"}, nil},
+		{"no trackers", []string{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := service.Resolve(tt.trackers)
+			if tt.want == nil {
+				assert.ErrorIs(t, err, retentionpolicy.ErrTrackerNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Same(t, tt.want, tracker)
+		})
+	}
+}
+
+func TestService_ResolveLabels(t *testing.T) {
+	keep := &domain.Tracker{Name: "Keep", Keep: true}
+	movies := &domain.Tracker{Name: "Movies"}
+	service := NewService([]TrackerConfig{
+		{&domain.Tracker{Name: "My Tracker"}, regexp.MustCompile(`^tracker\.example$`)},
+	}, []TrackerConfig{
+		{keep, regexp.MustCompile(`^keep$`)},
+		{movies, regexp.MustCompile(`^movies`)},
+	})
+	tests := []struct {
+		name   string
+		labels []string
+		want   *domain.Tracker
+	}{
+		{"single label", []string{"movies-hd"}, movies},
+		{"first config wins over label order", []string{"movies-hd", "keep"}, keep},
+		{"pattern is matched exactly", []string{"keep-forever"}, nil},
+		{"tracker configs are ignored", []string{"tracker.example"}, nil},
+		{"no labels", []string{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := service.ResolveLabels(tt.labels)
+			if tt.want == nil {
+				assert.ErrorIs(t, err, retentionpolicy.ErrTrackerNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Same(t, tt.want, tracker)
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package ultraapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type Instance struct {
@@ -14,43 +16,43 @@ type Instance struct {
 	authToken  []byte
 }
 
-func New(endpoint string, authToken []byte) *Instance {
-	return &Instance{endpoint: endpoint, httpClient: http.DefaultClient, authToken: authToken}
+func New(endpoint string, authToken []byte, timeout time.Duration) *Instance {
+	return &Instance{endpoint: endpoint, httpClient: &http.Client{Timeout: timeout}, authToken: authToken}
 }
 
-func (instance Instance) GetTotalStats() (*TotalStats, error) {
+func (instance Instance) GetTotalStats(ctx context.Context) (*TotalStats, error) {
 	var totalStats TotalStats
-	err := instance.request("total-stats", &totalStats)
+	err := instance.request(ctx, "total-stats", &totalStats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total stats: %w", err)
 	}
 	return &totalStats, nil
 }
 
-func (instance Instance) GetTraffic() (*Traffic, error) {
+func (instance Instance) GetTraffic(ctx context.Context) (*Traffic, error) {
 	var traffic Traffic
-	err := instance.request("get-traffic", &traffic)
+	err := instance.request(ctx, "get-traffic", &traffic)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get traffic: %w", err)
 	}
 	return &traffic, nil
 }
 
-func (instance Instance) GetDiskQuota() (*DiskQuota, error) {
+func (instance Instance) GetDiskQuota(ctx context.Context) (*DiskQuota, error) {
 	var diskQuota DiskQuota
-	err := instance.request("get-diskquota", &diskQuota)
+	err := instance.request(ctx, "get-diskquota", &diskQuota)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disk quota: %w", err)
 	}
 	return &diskQuota, nil
 }
 
-func (instance Instance) request(endpointPath string, receivingValue any) (err error) {
+func (instance Instance) request(ctx context.Context, endpointPath string, receivingValue any) (err error) {
 	requestUrl, err := url.JoinPath(instance.endpoint, endpointPath)
 	if err != nil {
 		return fmt.Errorf("failed to join url: %w", err)
 	}
-	request, err := http.NewRequest("GET", requestUrl, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
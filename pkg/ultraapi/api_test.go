@@ -1,9 +1,11 @@
 package ultraapi
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestApiClient(t *testing.T) {
@@ -12,23 +14,23 @@ func TestApiClient(t *testing.T) {
 		t.Skipf("No ULTRA_ENDPOINT set, skipping")
 	}
 	authToken := []byte(os.Getenv("ULTRA_AUTH_TOKEN"))
-	apiClient := New(endpoint, authToken)
+	apiClient := New(endpoint, authToken, 30*time.Second)
 	t.Run("can get total stats", func(t *testing.T) {
-		totalStats, err := apiClient.GetTotalStats()
+		totalStats, err := apiClient.GetTotalStats(context.Background())
 		if err != nil {
 			t.Errorf("failed to get total stats: %v", err)
 		}
 		fmt.Printf("%T: %+v\n", totalStats, totalStats)
 	})
 	t.Run("can get traffic", func(t *testing.T) {
-		traffic, err := apiClient.GetTraffic()
+		traffic, err := apiClient.GetTraffic(context.Background())
 		if err != nil {
 			t.Errorf("failed to get traffic: %v", err)
 		}
 		fmt.Printf("%T: %+v\n", traffic, traffic)
 	})
 	t.Run("can get disk quota", func(t *testing.T) {
-		diskQuota, err := apiClient.GetDiskQuota()
+		diskQuota, err := apiClient.GetDiskQuota(context.Background())
 		if err != nil {
 			t.Errorf("failed to get disk quota: %v", err)
 		}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"context"
+	"sync"
+)
+
+// MapConcurrently calls fn for every item with up to parallelism calls running at the same time and returns the results
+// at the index of their item. The first error cancels the context of the remaining calls and is returned.
+func MapConcurrently[T, R any](ctx context.Context, items []T, parallelism int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	results := make([]R, len(items))
+	semaphore := make(chan struct{}, max(parallelism, 1))
+	wg := &sync.WaitGroup{}
+	for i, item := range items {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-semaphore }()
+			result, err := fn(ctx, item)
+			if err != nil {
+				// only the first error is kept as cause, the remaining calls fail due to the cancellation
+				cancel(err)
+				return
+			}
+			results[i] = result
+		})
+	}
+	wg.Wait()
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapConcurrently(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+	var concurrent, maxConcurrent atomic.Int64
+	results, err := MapConcurrently(context.Background(), items, 4, func(ctx context.Context, item int) (int, error) {
+		current := concurrent.Add(1)
+		defer concurrent.Add(-1)
+		for {
+			previous := maxConcurrent.Load()
+			if current <= previous || maxConcurrent.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return item * 2, nil
+	})
+	if err != nil {
+		t.Fatalf("MapConcurrently() error = %v", err)
+	}
+	for i, result := range results {
+		assert.Equal(t, i*2, result)
+	}
+	assert.LessOrEqual(t, maxConcurrent.Load(), int64(4))
+}
+
+func TestMapConcurrently_Error(t *testing.T) {
+	errFailed := errors.New("failed")
+	var calls atomic.Int64
+	_, err := MapConcurrently(context.Background(), make([]int, 100), 2, func(ctx context.Context, item int) (int, error) {
+		if calls.Add(1) == 3 {
+			return 0, errFailed
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Millisecond):
+			return item, nil
+		}
+	})
+	assert.ErrorIs(t, err, errFailed)
+	// the remaining items are not started after the first error
+	assert.Less(t, calls.Load(), int64(100))
+}
@@ -15,12 +15,18 @@ document.addEventListener('DOMContentLoaded', () => {
             tooltip.innerHTML = "";
             const decision = getDecisionStr(trigger.dataset.decision);
             const torrentStatus = trigger.dataset.torrentStatus;
+            const torrentState = trigger.dataset.torrentState;
+            const torrentLabels = trigger.dataset.torrentLabels;
             const torrentRatio = trigger.dataset.torrentRatio;
             const torrentAge = trigger.dataset.torrentAge;
+            const torrentSeedingTime = trigger.dataset.torrentSeedingTime;
+            const torrentCount = Number(trigger.dataset.torrentCount);
 
             const trackerName = trigger.dataset.trackerName;
+            const trackerStatus = trigger.dataset.trackerStatus;
             const trackerMinRatio = formatFloatStr(trigger.dataset.trackerMinRatio);
             const trackerMinAge = trigger.dataset.trackerMinAge;
+            const trackerMinSeedTime = trigger.dataset.trackerMinSeedTime;
 
             const decisionElem = document.createElement("div");
             decisionElem.classList.add("font-bold");
@@ -33,6 +39,26 @@ document.addEventListener('DOMContentLoaded', () => {
                     trackerNameElem.textContent = trackerName;
                     tooltip.append(trackerNameElem);
                 }
+                if (trackerStatus) {
+                    const trackerStatusElem = document.createElement("div");
+                    trackerStatusElem.textContent = `Tracker: ${trackerStatus}`;
+                    tooltip.append(trackerStatusElem);
+                }
+                if (torrentState && torrentState !== "seeding") {
+                    const torrentStateElem = document.createElement("div");
+                    torrentStateElem.textContent = `State: ${torrentState}`;
+                    tooltip.append(torrentStateElem);
+                }
+                if (torrentLabels) {
+                    const torrentLabelsElem = document.createElement("div");
+                    torrentLabelsElem.textContent = `Labels: ${torrentLabels}`;
+                    tooltip.append(torrentLabelsElem);
+                }
+                if (torrentCount > 1) {
+                    const torrentCountElem = document.createElement("div");
+                    torrentCountElem.textContent = `Torrents: ${torrentCount}`;
+                    tooltip.append(torrentCountElem);
+                }
                 if (torrentRatio !== "-1") {
                     const ratioElem = document.createElement("div");
                     ratioElem.textContent = `Ratio: ${formatFloatStr(torrentRatio)}`;
@@ -49,6 +75,11 @@ document.addEventListener('DOMContentLoaded', () => {
                     }
                     tooltip.append(ageElem);
                 }
+                if (torrentSeedingTime !== "-1" && trackerMinSeedTime !== "" && trackerMinSeedTime !== "0") {
+                    const seedingTimeElem = document.createElement("div");
+                    seedingTimeElem.textContent = `Seeding: ${nanosecondsToDays(Number(torrentSeedingTime))}d/${nanosecondsToDays(Number(trackerMinSeedTime))}d`;
+                    tooltip.append(seedingTimeElem);
+                }
             } else {
                 const torrentInfoElem = document.createElement("div");
                 torrentInfoElem.textContent = "No torrent entry";
@@ -105,6 +136,8 @@ function getDecisionStr(decision) {
         return "Safe to delete"
     } else if (decision === "pending") {
         return "Status pending"
+    } else if (decision === "unregistered") {
+        return "Unregistered at tracker"
     } else {
         return "???"
     }